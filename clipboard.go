@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// clipboardBackend is one way of putting text on the clipboard: by copying
+// it directly, or with an escape sequence for the terminal to act on.
+type clipboardBackend struct {
+	name      string
+	available func() bool
+	copy      func(text string) error
+	sequence  func(text string) string
+}
+
+// clipboardBackends are tried in order until one is available. OSC 52 comes
+// last: it works over SSH and inside tmux, but only if the terminal allows
+// programs to set the clipboard, and there is no way to tell whether it did.
+var clipboardBackends = []clipboardBackend{
+	{
+		name:      "wl-copy",
+		available: func() bool { return os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wl-copy") },
+		copy:      func(text string) error { return pipeTo(text, "wl-copy") },
+	},
+	{
+		name:      "xclip",
+		available: func() bool { return os.Getenv("DISPLAY") != "" && hasCommand("xclip") },
+		copy:      func(text string) error { return pipeTo(text, "xclip", "-selection", "clipboard") },
+	},
+	{
+		name:      "xsel",
+		available: func() bool { return os.Getenv("DISPLAY") != "" && hasCommand("xsel") },
+		copy:      func(text string) error { return pipeTo(text, "xsel", "--clipboard", "--input") },
+	},
+	{
+		name:      "pbcopy",
+		available: func() bool { return hasCommand("pbcopy") },
+		copy:      func(text string) error { return pipeTo(text, "pbcopy") },
+	},
+	{
+		name:      "osc52",
+		available: func() bool { return true },
+		sequence:  osc52Sequence,
+	},
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// pipeTo runs name with args, writing text to its standard input.
+func pipeTo(text, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// osc52Sequence returns the sequence asking the terminal to set its
+// clipboard, wrapped so tmux and screen pass it through to the outer
+// terminal.
+func osc52Sequence(text string) string {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	return seq.String()
+}
+
+// copyToClipboard copies text with the backend named by preferred, or with
+// the first available one when preferred is empty. It returns the name of
+// the backend used, and the escape sequence the view has to write for
+// backends that go through the terminal.
+func copyToClipboard(text, preferred string) (string, string, error) {
+	for _, backend := range clipboardBackends {
+		if preferred != "" && backend.name != preferred {
+			continue
+		}
+		if !backend.available() {
+			if preferred != "" {
+				return "", "", fmt.Errorf("clipboard backend %q is not available", preferred)
+			}
+			continue
+		}
+		if backend.sequence != nil {
+			return backend.name, backend.sequence(text), nil
+		}
+		return backend.name, "", backend.copy(text)
+	}
+	if preferred != "" {
+		return "", "", fmt.Errorf("unknown clipboard backend %q", preferred)
+	}
+	return "", "", fmt.Errorf("no clipboard available")
+}
+
+// clipboardWrittenMsg is sent once the view has written a clipboard
+// sequence.
+type clipboardWrittenMsg struct{}
+
+// copyCommand copies a command of the current slide, and notifies which
+// backend it went through.
+func copyCommand(m *model, command string) tea.Cmd {
+	backend, sequence, err := copyToClipboard(command, m.config.Clipboard)
+	if err != nil {
+		m.notification = fmt.Sprintf("Copy error: %v", err)
+	} else {
+		// Truncate command text to fit notification bar
+		prefix := fmt.Sprintf("Copied via %s: ", backend)
+		m.notification = prefix + ansi.Truncate(command, max(m.width-4-len(prefix), 3), "...")
+	}
+	m.notificationTimer = 3 // Show for 3 seconds
+	if sequence == "" {
+		return doTick()
+	}
+	// The sequence goes out with the next frame, so it doesn't interleave
+	// with the renderer's own output
+	m.clipboardSequence = sequence
+	return tea.Batch(doTick(), tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return clipboardWrittenMsg{}
+	}))
+}
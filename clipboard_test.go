@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCopyToClipboardBackends(t *testing.T) {
+	var copied []string
+	backend := func(name string, available bool) clipboardBackend {
+		return clipboardBackend{
+			name:      name,
+			available: func() bool { return available },
+			copy: func(text string) error {
+				copied = append(copied, name+":"+text)
+				return nil
+			},
+		}
+	}
+	failing := backend("failing", true)
+	failing.copy = func(string) error { return errors.New("broken pipe") }
+	saved := clipboardBackends
+	defer func() { clipboardBackends = saved }()
+	clipboardBackends = []clipboardBackend{
+		backend("absent", false),
+		backend("present", true),
+		failing,
+		{name: "osc52", available: func() bool { return true }, sequence: func(text string) string { return "<" + text + ">" }},
+	}
+
+	tests := []struct {
+		preferred, backend, sequence, err string
+	}{
+		{"", "present", "", ""},
+		{"present", "present", "", ""},
+		{"osc52", "osc52", "<ls>", ""},
+		{"absent", "", "", `clipboard backend "absent" is not available`},
+		{"failing", "failing", "", "broken pipe"},
+		{"nope", "", "", `unknown clipboard backend "nope"`},
+	}
+	for _, tt := range tests {
+		copied = nil
+		backend, sequence, err := copyToClipboard("ls", tt.preferred)
+		if backend != tt.backend || sequence != tt.sequence {
+			t.Errorf("copyToClipboard(%q) = %q, %q; want %q, %q", tt.preferred, backend, sequence, tt.backend, tt.sequence)
+		}
+		if got := errorString(err); got != tt.err {
+			t.Errorf("copyToClipboard(%q) error = %q; want %q", tt.preferred, got, tt.err)
+		}
+		if tt.backend == "present" && (len(copied) != 1 || copied[0] != "present:ls") {
+			t.Errorf("copyToClipboard(%q) copied %q", tt.preferred, copied)
+		}
+	}
+
+	clipboardBackends = []clipboardBackend{backend("absent", false)}
+	if _, _, err := copyToClipboard("ls", ""); err == nil {
+		t.Error("copying with no backend available succeeded")
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestCommandHotkeys(t *testing.T) {
+	var lines []string
+	for i := range len(commandKeys) {
+		lines = append(lines, "echo "+string(rune('0'+i)))
+	}
+	content := "# Commands\n\n```commands\n" + strings.Join(lines, "\n") + "\n```\n"
+	m := initialModel(".", "", nil, nil)
+	next, _ := m.update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	next, _ = next.(model).update(slidesLoadedMsg{
+		slides: []slide{newSlide(content, content, slideMeta{}, "01.md", -1)},
+		config: deckConfig{Clipboard: "osc52"},
+	})
+	m = next.(model)
+	if len(m.slides[0].commands) != len(commandKeys) {
+		t.Fatalf("slide has %d commands, want %d", len(m.slides[0].commands), len(commandKeys))
+	}
+
+	for i, key := range commandKeys {
+		next, _ := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		got := next.(model)
+		if want := osc52Sequence(lines[i]); got.clipboardSequence != want {
+			t.Errorf("%q copied %q, want %q", key, got.clipboardSequence, want)
+		}
+	}
+}
+
+func TestTimerKeysWithoutCommands(t *testing.T) {
+	content := "# Commands\n\n```commands\necho one\n```\n"
+	m := initialModel(".", "", nil, nil)
+	next, _ := m.update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	next, _ = next.(model).update(slidesLoadedMsg{
+		slides: []slide{newSlide(content, content, slideMeta{}, "01.md", -1)},
+		config: deckConfig{Clipboard: "osc52"},
+	})
+	m = next.(model)
+	m.timerDuration = time.Minute
+	m.timerElapsed = 30 * time.Second
+
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = next.(model)
+	if !m.waitingForReset || m.clipboardSequence != "" {
+		t.Fatalf("p didn't ask to reset the timer")
+	}
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = next.(model)
+	if m.waitingForReset || m.timerElapsed != 0 || m.clipboardSequence != "" {
+		t.Errorf("y didn't reset the timer")
+	}
+}
@@ -0,0 +1,723 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+// slideSeparator is the horizontal rule that splits a single-file deck into
+// slides. It must sit on its own line outside of code fences.
+const slideSeparator = "---"
+
+// deckConfigFile holds deck metadata when present in the deck directory.
+const deckConfigFile = "deck.yaml"
+
+// deckConfig is deck-wide metadata. It is read from deck.yaml, then from the
+// front matter of the first slide, then from the legacy _title.md,
+// _author.md, _theme.md and _time files, with earlier sources winning.
+type deckConfig struct {
+	Title      string          `yaml:"title"`
+	Author     string          `yaml:"author"`
+	Theme      string          `yaml:"theme"`
+	Duration   string          `yaml:"duration"`  // minutes, or a Go duration such as "1h15m"
+	WordWrap   int             `yaml:"word_wrap"` // maximum wrap width; 0 follows the terminal
+	StatusBar  statusBarColors `yaml:"status_bar"`
+	Run        runConfig       `yaml:"run"`
+	Clipboard  string          `yaml:"clipboard"` // backend to copy commands with; detected when empty
+	Target     targetSize      `yaml:"target"`
+	Fit        *bool           `yaml:"fit"`          // choose each slide's wrap width to fill the screen; nil when unset
+	Center     string          `yaml:"center"`       // "horizontal", "vertical" or "both"; top left when empty
+	Images     string          `yaml:"images"`       // how to draw images; detected when empty
+	Reveal     string          `yaml:"reveal"`       // "dim" to show unrevealed fragments dimmed
+	TypeSpeed  int             `yaml:"typing_speed"` // characters per second code morphs type at
+	Transition string          `yaml:"transition"`   // how to move between slides; none when empty
+	Mouse      *bool           `yaml:"mouse"`        // capture the mouse to scroll with the wheel; nil when unset
+}
+
+// targetSize is the terminal size the deck is meant to be presented at,
+// which `slidetty lint` checks slides against.
+type targetSize struct {
+	Width  int `yaml:"width"`
+	Height int `yaml:"height"`
+}
+
+// runConfig controls how command blocks are executed live.
+type runConfig struct {
+	Shell string   `yaml:"shell"` // defaults to $SHELL, then /bin/sh
+	Dir   string   `yaml:"dir"`   // working directory, relative to the deck
+	Allow []string `yaml:"allow"` // commands run without confirmation; * matches anything
+}
+
+// statusBarColors overrides the colors of the three-section status line.
+type statusBarColors struct {
+	Outer      string `yaml:"outer"` // slide count and title sections
+	Inner      string `yaml:"inner"` // author section
+	Foreground string `yaml:"foreground"`
+}
+
+// withDefaults fills unset colors with the built-in navy palette.
+func (c statusBarColors) withDefaults() statusBarColors {
+	if c.Outer == "" {
+		c.Outer = "#000080"
+	}
+	if c.Inner == "" {
+		c.Inner = "#1E3A8A"
+	}
+	if c.Foreground == "" {
+		c.Foreground = "15"
+	}
+	return c
+}
+
+// merge fills every unset field of c from other.
+func (c deckConfig) merge(other deckConfig) deckConfig {
+	if c.Title == "" {
+		c.Title = other.Title
+	}
+	if c.Author == "" {
+		c.Author = other.Author
+	}
+	if c.Theme == "" {
+		c.Theme = other.Theme
+	}
+	if c.Duration == "" {
+		c.Duration = other.Duration
+	}
+	if c.WordWrap == 0 {
+		c.WordWrap = other.WordWrap
+	}
+	if c.StatusBar.Outer == "" {
+		c.StatusBar.Outer = other.StatusBar.Outer
+	}
+	if c.StatusBar.Inner == "" {
+		c.StatusBar.Inner = other.StatusBar.Inner
+	}
+	if c.StatusBar.Foreground == "" {
+		c.StatusBar.Foreground = other.StatusBar.Foreground
+	}
+	if c.Run.Shell == "" {
+		c.Run.Shell = other.Run.Shell
+	}
+	if c.Run.Dir == "" {
+		c.Run.Dir = other.Run.Dir
+	}
+	if c.Run.Allow == nil {
+		c.Run.Allow = other.Run.Allow
+	}
+	if c.Clipboard == "" {
+		c.Clipboard = other.Clipboard
+	}
+	if c.Target.Width == 0 {
+		c.Target.Width = other.Target.Width
+	}
+	if c.Target.Height == 0 {
+		c.Target.Height = other.Target.Height
+	}
+	if c.Fit == nil {
+		c.Fit = other.Fit
+	}
+	if c.Center == "" {
+		c.Center = other.Center
+	}
+	if c.Images == "" {
+		c.Images = other.Images
+	}
+	if c.Reveal == "" {
+		c.Reveal = other.Reveal
+	}
+	if c.TypeSpeed == 0 {
+		c.TypeSpeed = other.TypeSpeed
+	}
+	if c.Transition == "" {
+		c.Transition = other.Transition
+	}
+	if c.Mouse == nil {
+		c.Mouse = other.Mouse
+	}
+	return c
+}
+
+// themeName returns the glamour style to render with, "auto" when unset.
+func (c deckConfig) themeName() string {
+	if c.Theme == "" {
+		return "auto"
+	}
+	return c.Theme
+}
+
+// capturesMouse reports whether the deck asks for the mouse, which is off
+// unless set.
+func (c deckConfig) capturesMouse() bool {
+	return c.Mouse != nil && *c.Mouse
+}
+
+// duration returns the configured presentation length, 0 when unset or
+// unparsable.
+func (c deckConfig) duration() time.Duration {
+	d, err := parseMinutes(c.Duration)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// slideMeta is per-slide front matter.
+type slideMeta struct {
+	Title      string `yaml:"title"`
+	Notes      string `yaml:"notes"`
+	Layout     string `yaml:"layout"`
+	Reveal     string `yaml:"reveal"`     // overrides the deck's reveal setting
+	Transition string `yaml:"transition"` // transition into the slide, overriding the deck's
+	Time       string `yaml:"time"`       // time budget, same format as deckConfig.Duration
+	Skip       bool   `yaml:"skip"`
+}
+
+// slide is one slide of the deck together with where it came from.
+type slide struct {
+	path     string // file the slide was loaded from
+	section  int    // section within path for single-file decks, -1 for whole files
+	raw      string // source text shown in the editor, front matter included
+	content  string // markdown body with front matter removed
+	meta     slideMeta
+	notes    string // speaker notes from front matter and the slide body
+	reveal   revealConfig
+	commands []string
+
+	sectionName string // named section of the deck's manifest, if any
+	deckReveal  string // the deck's reveal setting
+}
+
+// newSlide builds a slide from its markdown body and analyses it for reveal
+// directives, command blocks and speaker notes.
+func newSlide(raw, content string, meta slideMeta, path string, section int) slide {
+	return slide{
+		path:     path,
+		section:  section,
+		raw:      raw,
+		content:  content,
+		meta:     meta,
+		notes:    parseNotes(content, meta.Notes),
+		reveal:   analyzeReveal(content),
+		commands: parseCommandBlocks(content),
+	}
+}
+
+// withRaw returns the slide re-parsed from edited source text. Whole-file
+// slides carry their own front matter; slides of a single-file deck keep the
+// metadata of the section before them.
+func (s slide) withRaw(raw string) slide {
+	var edited slide
+	if s.section >= 0 {
+		edited = newSlide(raw, raw, s.meta, s.path, s.section)
+	} else {
+		front, body := splitFrontMatter(raw)
+		meta, _, _ := parseFrontMatter(front)
+		edited = newSlide(raw, body, meta, s.path, s.section)
+	}
+	edited.sectionName = s.sectionName
+	edited.deckReveal = s.deckReveal
+	return edited
+}
+
+// key identifies the slide by where it lives on disk, so it can be found
+// again after the deck is reloaded and indexes have shifted.
+func (s slide) key() string {
+	return fmt.Sprintf("%s#%d", s.path, s.section)
+}
+
+// timeBudget returns the time the slide is expected to take, 0 when unset.
+func (s slide) timeBudget() time.Duration {
+	d, err := parseMinutes(s.meta.Time)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// parseMinutes parses a plain number of minutes, as used by the _time file,
+// or a Go duration string.
+func parseMinutes(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("empty duration")
+	}
+	if minutes, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(minutes * float64(time.Minute)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+// frontMatterKeys are the keys recognised in front matter. Requiring every
+// key to be known keeps ordinary markdown between separators from being
+// mistaken for metadata.
+var frontMatterKeys = map[string]bool{
+	"title": true, "author": true, "theme": true, "duration": true,
+	"word_wrap": true, "status_bar": true, "run": true, "clipboard": true,
+	"target": true, "fit": true, "center": true, "images": true,
+	"reveal": true, "typing_speed": true, "transition": true, "mouse": true,
+	"notes": true, "layout": true, "time": true, "skip": true,
+}
+
+// parseFrontMatter decodes text as front matter, returning both its slide
+// and deck fields. ok is false unless text is a non-empty YAML mapping made
+// only of known keys.
+func parseFrontMatter(text string) (meta slideMeta, cfg deckConfig, ok bool) {
+	if isBlankSection(text) {
+		return meta, cfg, false
+	}
+	var keys map[string]any
+	if err := yaml.Unmarshal([]byte(text), &keys); err != nil || len(keys) == 0 {
+		return meta, cfg, false
+	}
+	for key := range keys {
+		if !frontMatterKeys[key] {
+			return meta, cfg, false
+		}
+	}
+	if err := yaml.Unmarshal([]byte(text), &meta); err != nil {
+		return slideMeta{}, cfg, false
+	}
+	if err := yaml.Unmarshal([]byte(text), &cfg); err != nil {
+		return slideMeta{}, deckConfig{}, false
+	}
+	return meta, cfg, true
+}
+
+// splitFrontMatter separates a leading front matter block, delimited by
+// separator lines, from the rest of a slide file.
+func splitFrontMatter(content string) (front, body string) {
+	sections := splitDeck(content)
+	if len(sections) < 3 || sections[0] != "" {
+		return "", content
+	}
+	if _, _, ok := parseFrontMatter(sections[1]); !ok {
+		return "", content
+	}
+	return sections[1], joinDeck(sections[2:])
+}
+
+// fenceMarker returns the character and length of the fence a line starts
+// with, three or more backticks or tildes, and the info string after it. char
+// is 0 when the line is not a fence.
+func fenceMarker(line string) (char byte, size int, info string) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if trimmed == "" || (trimmed[0] != '`' && trimmed[0] != '~') {
+		return 0, 0, ""
+	}
+	for size < len(trimmed) && trimmed[size] == trimmed[0] {
+		size++
+	}
+	if size < 3 {
+		return 0, 0, ""
+	}
+	return trimmed[0], size, strings.TrimSpace(trimmed[size:])
+}
+
+// isFenceLine reports whether line looks like it opens or closes a fenced
+// code block. Use a codeFence to tell which lines are inside one.
+func isFenceLine(line string) bool {
+	char, _, _ := fenceMarker(line)
+	return char != 0
+}
+
+// codeFence follows fenced code blocks through a document, line by line. As
+// in CommonMark, a block is closed only by a fence of the character that
+// opened it, at least as long, with no info string, so ``` inside a ````
+// block or ~~~ inside a ``` block is code.
+type codeFence struct {
+	char byte // fence character of the open block, 0 outside blocks
+	size int
+}
+
+// openFence returns the block the fence line opens.
+func openFence(line string) codeFence {
+	var f codeFence
+	f.step(line)
+	return f
+}
+
+// inside reports whether the lines stepped over leave a block open.
+func (f codeFence) inside() bool {
+	return f.char != 0
+}
+
+// closes reports whether line closes the open block.
+func (f codeFence) closes(line string) bool {
+	char, size, info := fenceMarker(line)
+	return f.inside() && char == f.char && size >= f.size && info == ""
+}
+
+// step moves past line, and reports whether it opened or closed a block.
+func (f *codeFence) step(line string) bool {
+	if f.inside() {
+		if !f.closes(line) {
+			return false
+		}
+		*f = codeFence{}
+		return true
+	}
+	char, size, info := fenceMarker(line)
+	if char == 0 || char == '`' && strings.Contains(info, "`") {
+		return false
+	}
+	*f = codeFence{char: char, size: size}
+	return true
+}
+
+// splitDeck splits the contents of a single-file deck into sections on
+// separator lines. Separators inside code fences are left alone. Each section
+// keeps its trailing newline, and empty sections are kept too, so that
+// joinDeck(splitDeck(s)) reproduces s and section indexes stay stable for
+// writing edits back.
+func splitDeck(content string) []string {
+	var sections []string
+	var current strings.Builder
+	var fence codeFence
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		fence.step(strings.TrimRight(line, "\r\n"))
+		if !fence.inside() && strings.TrimRight(line, " \t\r\n") == slideSeparator {
+			sections = append(sections, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteString(line)
+	}
+	return append(sections, current.String())
+}
+
+// joinDeck is the inverse of splitDeck. Sections other than the last are
+// given a trailing newline if they lack one so separators stay on their own
+// line.
+func joinDeck(sections []string) string {
+	var b strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			b.WriteString(slideSeparator + "\n")
+		}
+		b.WriteString(section)
+		if i < len(sections)-1 && section != "" && !strings.HasSuffix(section, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// isBlankSection reports whether a deck section has no content worth showing,
+// such as the empty section before a leading separator.
+func isBlankSection(section string) bool {
+	return strings.TrimSpace(section) == ""
+}
+
+// deckFileSlides turns the sections of a single-file deck into slides. A
+// section that is pure front matter applies to the section after it.
+func deckFileSlides(path, content string) (slides []slide, first string) {
+	sections := splitDeck(content)
+	pending := ""
+	for idx, section := range sections {
+		if _, _, ok := parseFrontMatter(section); ok {
+			pending = section
+			if len(slides) == 0 && first == "" {
+				first = section
+			}
+			continue
+		}
+		if isBlankSection(section) {
+			continue
+		}
+		meta, _, _ := parseFrontMatter(pending)
+		slides = append(slides, newSlide(section, section, meta, path, idx))
+		pending = ""
+	}
+	return slides, first
+}
+
+// fileSlide loads a slide that is a whole file, with optional front matter.
+func fileSlide(path, content string) (s slide, front string) {
+	front, body := splitFrontMatter(content)
+	meta, _, _ := parseFrontMatter(front)
+	return newSlide(content, body, meta, path, -1), front
+}
+
+// readSlide reads a single slide back from path. section selects the slide
+// within a single-file deck and is negative for one-file-per-slide decks.
+func readSlide(path string, section int) (slide, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return slide{}, err
+	}
+	if section < 0 {
+		s, _ := fileSlide(path, string(content))
+		return s, nil
+	}
+	sections := splitDeck(string(content))
+	if section >= len(sections) {
+		return slide{}, fmt.Errorf("%s has no slide section %d", path, section)
+	}
+	var meta slideMeta
+	if section > 0 {
+		meta, _, _ = parseFrontMatter(sections[section-1])
+	}
+	return newSlide(sections[section], sections[section], meta, path, section), nil
+}
+
+// writeSection replaces section index of the deck file at path with content,
+// leaving every other section untouched. A negative section replaces the
+// whole file. Content that would split into several sections is refused, as
+// it would shift every later slide's section.
+func writeSection(path string, section int, content string) error {
+	if section < 0 {
+		return os.WriteFile(path, []byte(content), 0o644)
+	}
+	if len(splitDeck(content)) > 1 {
+		return fmt.Errorf("the slide contains a %q separator line; add new slides in the deck file instead", slideSeparator)
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sections := splitDeck(string(existing))
+	if section >= len(sections) {
+		return fmt.Errorf("%s has no slide section %d", path, section)
+	}
+	sections[section] = content
+	return os.WriteFile(path, []byte(joinDeck(sections)), 0o644)
+}
+
+// sectionLine returns the 1-based line of path where the given section's
+// content starts, skipping leading blank lines. It is 1 for whole-file
+// slides or when the file can't be read.
+func sectionLine(path string, section int) int {
+	if section < 0 {
+		return 1
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 1
+	}
+	sections := splitDeck(string(content))
+	if section >= len(sections) {
+		return 1
+	}
+	line := 1
+	for _, s := range sections[:section] {
+		line += strings.Count(s, "\n") + 1 // the section and the separator after it
+	}
+	for _, l := range strings.SplitAfter(sections[section], "\n") {
+		if strings.TrimSpace(l) != "" || !strings.HasSuffix(l, "\n") {
+			break
+		}
+		line++
+	}
+	return line
+}
+
+// loadLegacyConfig reads deck metadata from the underscore files that
+// predate deck.yaml.
+func loadLegacyConfig(root string) deckConfig {
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(content))
+	}
+	return deckConfig{
+		Title:    read("_title.md"),
+		Author:   read("_author.md"),
+		Theme:    read("_theme.md"),
+		Duration: read("_time"),
+	}
+}
+
+// loadDeckYAML reads deck.yaml from root. A missing file is not an error.
+func loadDeckYAML(root string) (deckConfig, error) {
+	var cfg deckConfig
+	content, err := os.ReadFile(filepath.Join(root, deckConfigFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %v", deckConfigFile, err)
+	}
+	return cfg, nil
+}
+
+// isSlideFile reports whether name is a markdown slide rather than deck
+// metadata (files starting with an underscore).
+func isSlideFile(name string) bool {
+	return filepath.Ext(name) == ".md" && !strings.HasPrefix(name, "_")
+}
+
+// listSlideFiles returns the slide files in root, sorted by name, as paths
+// joined onto root.
+func listSlideFiles(root string) ([]string, error) {
+	files, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, file := range files {
+		if !file.IsDir() && isSlideFile(file.Name()) {
+			paths = append(paths, filepath.Join(root, file.Name()))
+		}
+	}
+
+	// Sort filenames to ensure consistent order
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// resolveDeck picks the directory a deck is loaded from and, for single-file
+// decks, the markdown file holding every slide. An explicit argument always
+// wins. Without one the current directory is used, unless it holds no slides
+// and a slides/ folder (as created by `slidetty init`) does.
+func resolveDeck(arg string) (root, file string, err error) {
+	if arg != "" {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return "", "", err
+		}
+		if !info.IsDir() {
+			if filepath.Ext(arg) != ".md" {
+				return "", "", fmt.Errorf("%s is neither a directory nor a markdown file", arg)
+			}
+			return filepath.Dir(arg), arg, nil
+		}
+		return arg, "", nil
+	}
+
+	if paths, err := listSlideFiles("."); err == nil && len(paths) > 0 {
+		return ".", "", nil
+	}
+	if info, err := os.Stat("slides"); err == nil && info.IsDir() {
+		return "slides", "", nil
+	}
+	return ".", "", nil
+}
+
+type slidesLoadedMsg struct {
+	slides []slide
+	config deckConfig
+}
+
+type slideReloadedMsg struct {
+	slideIndex int
+	slide      slide
+}
+
+// deckSlides loads every slide of the deck in root, skipped ones included,
+// and returns the front matter of the first one. When deckFile is set the
+// slides come from its separator-delimited sections, otherwise from the
+// deck's manifest if it has one, or from its slide files in name order.
+// including lists the decks that include this one.
+func deckSlides(root, deckFile string, including []string) (all []slide, firstFront string, err error) {
+	if deckFile != "" {
+		// Split a single-file deck into its sections
+		content, err := os.ReadFile(deckFile)
+		if err != nil {
+			return nil, "", err
+		}
+		all, firstFront = deckFileSlides(deckFile, string(content))
+		return all, firstFront, nil
+	}
+
+	manifest, ok, err := loadManifest(root)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		all, err := manifestSlides(root, manifest, append(including, deckID(root, deckFile)))
+		if err != nil {
+			return nil, "", err
+		}
+		if len(all) > 0 && all[0].section < 0 {
+			firstFront, _ = splitFrontMatter(all[0].raw)
+		}
+		return all, firstFront, nil
+	}
+
+	filenames, err := listSlideFiles(root)
+	if err != nil {
+		return nil, "", err
+	}
+	for i, filename := range filenames {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, "", err
+		}
+		s, front := fileSlide(filename, string(content))
+		if i == 0 {
+			firstFront = front
+		}
+		all = append(all, s)
+	}
+	return all, firstFront, nil
+}
+
+// readDeck loads the slides of the deck in root along with its metadata.
+// Slides marked skip are left out.
+func readDeck(root, deckFile string) ([]slide, deckConfig, error) {
+	all, firstFront, err := deckSlides(root, deckFile, nil)
+	if err != nil {
+		return nil, deckConfig{}, err
+	}
+
+	cfg, err := loadDeckYAML(root)
+	if err != nil {
+		return nil, deckConfig{}, err
+	}
+	_, frontCfg, _ := parseFrontMatter(firstFront)
+	cfg = cfg.merge(frontCfg).merge(loadLegacyConfig(root))
+
+	slides := make([]slide, 0, len(all))
+	for _, s := range all {
+		if !s.meta.Skip {
+			s.deckReveal = cfg.Reveal
+			slides = append(slides, s)
+		}
+	}
+	return slides, cfg, nil
+}
+
+// loadSlides loads the deck as a tea.Cmd.
+func loadSlides(root, deckFile string) tea.Cmd {
+	return func() tea.Msg {
+		slides, cfg, err := readDeck(root, deckFile)
+		if err != nil {
+			return errMsg(err)
+		}
+		return slidesLoadedMsg{slides: slides, config: cfg}
+	}
+}
+
+// reloadSlide re-reads the slide at slideIndex from path, the file it was
+// originally loaded from, so reloading never depends on the process cwd or
+// on the directory listing staying unchanged.
+func reloadSlide(slideIndex int, path string, section int) tea.Cmd {
+	return func() tea.Msg {
+		if path == "" {
+			return errMsg(fmt.Errorf("invalid slide index: %d", slideIndex))
+		}
+
+		// Read the specific slide content
+		s, err := readSlide(path, section)
+		if err != nil {
+			return errMsg(err)
+		}
+		return slideReloadedMsg{slideIndex: slideIndex, slide: s}
+	}
+}
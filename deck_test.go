@@ -0,0 +1,228 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSplitDeck(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"single", "# One\n", []string{"# One\n"}},
+		{"two slides", "# One\n---\n# Two\n", []string{"# One\n", "# Two\n"}},
+		{"front matter", "---\ntitle: x\n---\n# One\n", []string{"", "title: x\n", "# One\n"}},
+		{"trailing spaces", "# One\n---  \n# Two", []string{"# One\n", "# Two"}},
+		{"separator in fence", "```yaml\n---\n```\n---\n# Two\n", []string{"```yaml\n---\n```\n", "# Two\n"}},
+		{"separator in tilde fence", "~~~\n---\n~~~\n", []string{"~~~\n---\n~~~\n"}},
+		{"empty section", "# One\n---\n---\n# Three\n", []string{"# One\n", "", "# Three\n"}},
+		{"longer fence", "````md\n```\n---\n```\n````\n---\n# Two\n", []string{"````md\n```\n---\n```\n````\n", "# Two\n"}},
+		{"tilde in backtick fence", "```\n~~~\n---\n```\n---\n# Two\n", []string{"```\n~~~\n---\n```\n", "# Two\n"}},
+		{"fence closed with info", "```\n``` go\n---\n```\n", []string{"```\n``` go\n---\n```\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitDeck(tt.content)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitDeck(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+			if joined := joinDeck(got); joined != tt.content && tt.name != "trailing spaces" {
+				t.Errorf("joinDeck(splitDeck(%q)) = %q", tt.content, joined)
+			}
+		})
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	lines := []string{"````md", "```go", "x := 1", "```", "````", "~~~", "```", "~~~~", "text"}
+	want := []bool{true, true, true, true, false, true, true, false, false}
+	var fence codeFence
+	for i, line := range lines {
+		fence.step(line)
+		if fence.inside() != want[i] {
+			t.Errorf("after %q inside = %v, want %v", line, fence.inside(), want[i])
+		}
+	}
+	if fence := openFence("```"); fence.closes("``") || !fence.closes("````") || fence.closes("~~~") {
+		t.Errorf("``` block closes on the wrong fences")
+	}
+}
+
+func TestWriteSectionRejectsSeparators(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deck.md")
+	deck := "# One\n---\n# Two\n---\n# Three\n"
+	if err := os.WriteFile(path, []byte(deck), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSection(path, 1, "# Two\n---\n# Two and a half\n"); err == nil {
+		t.Error("writeSection accepted a new separator")
+	}
+	if err := writeSection(path, 1, "# 2\n```yaml\n---\n```\n"); err != nil {
+		t.Fatalf("writeSection: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if want := "# One\n---\n# 2\n```yaml\n---\n```\n---\n# Three\n"; string(got) != want {
+		t.Errorf("deck = %q, want %q", got, want)
+	}
+}
+
+func TestJoinDeckAddsNewlines(t *testing.T) {
+	if got, want := joinDeck([]string{"# One", "# Two"}), "# One\n---\n# Two"; got != want {
+		t.Errorf("joinDeck = %q, want %q", got, want)
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	meta, cfg, ok := parseFrontMatter("title: Branching\ntheme: light\nlayout: two-column\ntime: 2m\nskip: true\n")
+	if !ok {
+		t.Fatal("parseFrontMatter rejected valid front matter")
+	}
+	if meta.Title != "Branching" || meta.Layout != "two-column" || meta.Time != "2m" || !meta.Skip {
+		t.Errorf("meta = %+v", meta)
+	}
+	if cfg.Title != "Branching" || cfg.Theme != "light" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	for _, text := range []string{
+		"",
+		"   \n",
+		"title: x\nunknown: y\n",      // unknown keys make it a slide
+		"- a list\n- of items\n",      // not a mapping
+		"Just a sentence: with colon", // a string value key that isn't known
+	} {
+		if _, _, ok := parseFrontMatter(text); ok {
+			t.Errorf("parseFrontMatter(%q) accepted", text)
+		}
+	}
+}
+
+// setting returns a pointer to b, for the deck's on/off settings.
+func setting(b bool) *bool {
+	return &b
+}
+
+func TestDeckConfigMerge(t *testing.T) {
+	first := deckConfig{
+		Title:     "Deck",
+		WordWrap:  80,
+		StatusBar: statusBarColors{Outer: "#111111"},
+		Run:       runConfig{Allow: []string{}},
+		Fit:       setting(false),
+	}
+	later := deckConfig{
+		Title:     "Other",
+		Author:    "Someone",
+		WordWrap:  100,
+		StatusBar: statusBarColors{Outer: "#222222", Inner: "#333333"},
+		Run:       runConfig{Shell: "zsh", Allow: []string{"*"}},
+		Fit:       setting(true),
+		Mouse:     setting(true),
+	}
+	got := first.merge(later)
+	want := deckConfig{
+		Title:     "Deck",
+		Author:    "Someone",
+		WordWrap:  80,
+		StatusBar: statusBarColors{Outer: "#111111", Inner: "#333333"},
+		// An empty allow list is set, and allows nothing
+		Run:   runConfig{Shell: "zsh", Allow: []string{}},
+		Fit:   setting(false),
+		Mouse: setting(true),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merge = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDeckSettingsCanBeTurnedOff(t *testing.T) {
+	tests := []struct {
+		name, yaml, front string
+		mouse, fit        bool
+	}{
+		{"unset", "", "", false, false},
+		{"deck.yaml", "mouse: true\nfit: true\n", "", true, true},
+		{"front matter", "", "mouse: true\nfit: true\n", true, true},
+		{"deck.yaml turns it off", "mouse: false\nfit: false\n", "mouse: true\nfit: true\n", false, false},
+		{"deck.yaml turns it on", "mouse: true\nfit: true\n", "mouse: false\nfit: false\n", true, true},
+		{"front matter turns it off", "title: Deck\n", "mouse: false\nfit: false\n", false, false},
+		{"each from its own source", "fit: false\n", "mouse: true\nfit: true\n", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.yaml != "" {
+				if err := os.WriteFile(filepath.Join(dir, deckConfigFile), []byte(tt.yaml), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			first := "# One\n"
+			if tt.front != "" {
+				first = "---\n" + tt.front + "---\n" + first
+			}
+			if err := os.WriteFile(filepath.Join(dir, "01-one.md"), []byte(first), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			slides, cfg, err := readDeck(dir, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(slides) != 1 || strings.Contains(slides[0].content, "mouse") {
+				t.Fatalf("front matter was taken for slide text: %q", slides[0].content)
+			}
+			if cfg.capturesMouse() != tt.mouse || cfg.fits() != tt.fit {
+				t.Errorf("mouse = %v, fit = %v; want %v, %v", cfg.capturesMouse(), cfg.fits(), tt.mouse, tt.fit)
+			}
+		})
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	front, body := splitFrontMatter("---\ntitle: Intro\n---\n# Intro\n---\n# More\n")
+	if front != "title: Intro\n" || body != "# Intro\n---\n# More\n" {
+		t.Errorf("splitFrontMatter = %q, %q", front, body)
+	}
+	content := "---\nnot: front matter\n---\n# Slide\n"
+	if front, body := splitFrontMatter(content); front != "" || body != content {
+		t.Errorf("splitFrontMatter(%q) = %q, %q; want no front matter", content, front, body)
+	}
+}
+
+func TestEditorKeepsRejectedSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deck.md")
+	deck := "# One\n---\n# Two\n"
+	if err := os.WriteFile(path, []byte(deck), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	slides, cfg, err := readDeck(filepath.Dir(path), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newStaticModel(filepath.Dir(path), path, slides, cfg, 80, 24)
+	next, _ := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m = next.(model)
+	m.editor.textarea.SetValue("# One\n---\n# One and a half\n")
+
+	next, _ = m.update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = next.(model)
+	if !m.showEditor || m.err != nil || !strings.Contains(m.editor.message, "not saved") {
+		t.Fatalf("rejected save: editor open %v, err %v, message %q", m.showEditor, m.err, m.editor.message)
+	}
+	if got, _ := os.ReadFile(path); string(got) != deck {
+		t.Errorf("deck changed to %q", got)
+	}
+	for range 2 {
+		next, _ = m.update(tea.KeyMsg{Type: tea.KeyEsc})
+		m = next.(model)
+	}
+	if m.showEditor || strings.Contains(m.View(), "Error:") {
+		t.Errorf("closing the editor after a rejected save doesn't return to the slides:\n%s", m.View())
+	}
+}
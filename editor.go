@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxUndo caps how many edits can be undone.
+const maxUndo = 200
+
+// editorState is a point in the editor's history.
+type editorState struct {
+	value    string
+	row, col int
+}
+
+// slideEditor edits a slide's markdown next to a live preview of how the
+// audience will see it.
+type slideEditor struct {
+	textarea textarea.Model
+	search   textinput.Model
+	base     slide // the slide being edited, for path, section and metadata
+	step     int   // reveal step the preview shows
+	saved    string
+	theme    string // for the renderers of preview columns
+	wrap     int    // preview wrap width
+	renderer *glamour.TermRenderer
+	preview  string
+
+	undo  []editorState
+	redo  []editorState
+	typed rune // the rune the last edit typed, 0 if it was another kind of edit
+
+	searching      bool
+	confirmDiscard bool
+	message        string
+}
+
+// newSlideEditor opens s for editing, its preview shown at reveal step.
+func newSlideEditor(s slide, step int, theme string, width, height int) slideEditor {
+	ta := textarea.New()
+	ta.ShowLineNumbers = true
+	ta.MaxHeight = 999
+	ta.Placeholder = "Edit slide markdown..."
+	// ctrl+f searches instead of moving the cursor
+	ta.KeyMap.CharacterForward = key.NewBinding(key.WithKeys("right"))
+	ta.SetValue(s.raw)
+	ta.Focus()
+
+	search := textinput.New()
+	search.Prompt = "find: "
+
+	e := slideEditor{textarea: ta, search: search, base: s, step: step, saved: s.raw}
+	e.resize(theme, width, height)
+	e.moveTo(0, 0)
+	return e
+}
+
+// dirty reports whether the editor holds changes that have not been saved.
+func (e *slideEditor) dirty() bool {
+	return e.textarea.Value() != e.saved
+}
+
+// paneWidths splits the screen between the markdown and the preview.
+func paneWidths(width int) (editor, preview int) {
+	editor = width / 2
+	return editor, width - editor
+}
+
+func (e *slideEditor) resize(theme string, width, height int) {
+	editorWidth, previewWidth := paneWidths(width)
+	e.textarea.SetWidth(editorWidth)
+	e.textarea.SetHeight(height - 3)
+	e.theme, e.wrap = theme, max(previewWidth-4, 10)
+	e.renderer = newRenderer(theme, e.wrap)
+	e.render()
+}
+
+// render refreshes the preview from the current markdown.
+func (e *slideEditor) render() {
+	s := e.base.withRaw(e.textarea.Value())
+	markdown := slideMarkdown(s, clampRevealProgress(e.step, s.reveal.totalItems()))
+	rendered, err := renderSlideLayout(e.renderer, e.theme, markdown, s.meta.Layout, e.wrap)
+	if err != nil {
+		rendered = "Error rendering markdown: " + err.Error()
+	}
+	e.preview = rendered
+}
+
+// position returns the cursor's line and rune offset within it.
+func (e *slideEditor) position() (row, col int) {
+	info := e.textarea.LineInfo()
+	return e.textarea.Line(), info.StartColumn + info.ColumnOffset
+}
+
+// moveTo places the cursor at rune col of line row.
+func (e *slideEditor) moveTo(row, col int) {
+	for e.textarea.Line() > row {
+		e.textarea.CursorUp()
+	}
+	for e.textarea.Line() < row {
+		before := e.textarea.Line()
+		e.textarea.CursorDown()
+		if e.textarea.Line() == before && e.textarea.LineInfo().RowOffset+1 >= e.textarea.LineInfo().Height {
+			break
+		}
+	}
+	e.textarea.SetCursor(col)
+}
+
+func (e *slideEditor) snapshot() editorState {
+	row, col := e.position()
+	return editorState{value: e.textarea.Value(), row: row, col: col}
+}
+
+func (e *slideEditor) restore(state editorState) {
+	e.textarea.SetValue(state.value)
+	e.moveTo(state.row, state.col)
+	e.typed = 0
+	e.render()
+}
+
+func pushState(stack []editorState, state editorState) []editorState {
+	stack = append(stack, state)
+	if len(stack) > maxUndo {
+		stack = stack[len(stack)-maxUndo:]
+	}
+	return stack
+}
+
+func (e *slideEditor) undoEdit() {
+	if len(e.undo) == 0 {
+		e.message = "nothing to undo"
+		return
+	}
+	e.redo = pushState(e.redo, e.snapshot())
+	state := e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.restore(state)
+}
+
+func (e *slideEditor) redoEdit() {
+	if len(e.redo) == 0 {
+		e.message = "nothing to redo"
+		return
+	}
+	e.undo = pushState(e.undo, e.snapshot())
+	state := e.redo[len(e.redo)-1]
+	e.redo = e.redo[:len(e.redo)-1]
+	e.restore(state)
+}
+
+// find moves the cursor to the next case-insensitive match of the search
+// query after the cursor, wrapping around the end of the slide.
+func (e *slideEditor) find() {
+	query := []rune(strings.ToLower(e.search.Value()))
+	if len(query) == 0 {
+		return
+	}
+	lines := strings.Split(strings.ToLower(e.textarea.Value()), "\n")
+	row, col := e.position()
+	matches, current := 0, 0
+	target := [2]int{-1, -1}
+	for r, line := range lines {
+		runes := []rune(line)
+		for c := 0; c+len(query) <= len(runes); c++ {
+			if string(runes[c:c+len(query)]) != string(query) {
+				continue
+			}
+			matches++
+			after := r > row || (r == row && c > col)
+			if target[0] < 0 && after {
+				target, current = [2]int{r, c}, matches
+			}
+		}
+	}
+	if matches == 0 {
+		e.message = "no match"
+		return
+	}
+	if target[0] < 0 {
+		// Wrap around to the first match
+		for r, line := range lines {
+			if c := strings.Index(line, string(query)); c >= 0 {
+				target, current = [2]int{r, len([]rune(line[:c]))}, 1
+				break
+			}
+		}
+	}
+	e.moveTo(target[0], target[1])
+	e.message = fmt.Sprintf("match %d of %d", current, matches)
+}
+
+// update handles every message other than closing and saving, which the
+// model does.
+func (e slideEditor) update(msg tea.Msg) (slideEditor, tea.Cmd) {
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if !isKey {
+		var cmd tea.Cmd
+		e.textarea, cmd = e.textarea.Update(msg)
+		return e, cmd
+	}
+	e.message = ""
+	e.confirmDiscard = false
+
+	if e.searching {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			e.searching = false
+			e.search.Blur()
+			return e, e.textarea.Focus()
+		case tea.KeyEnter, tea.KeyCtrlF:
+			e.find()
+			return e, nil
+		}
+		var cmd tea.Cmd
+		e.search, cmd = e.search.Update(msg)
+		return e, cmd
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyCtrlZ:
+		e.undoEdit()
+		return e, nil
+	case tea.KeyCtrlY:
+		e.redoEdit()
+		return e, nil
+	case tea.KeyCtrlF:
+		e.searching = true
+		e.textarea.Blur()
+		e.search.SetValue("")
+		return e, e.search.Focus()
+	}
+
+	before := e.snapshot()
+	var cmd tea.Cmd
+	e.textarea, cmd = e.textarea.Update(msg)
+	if e.textarea.Value() == before.value {
+		e.typed = 0
+		return e, cmd
+	}
+
+	// A typed word and the spaces after it are undone as one edit; anything
+	// else is its own
+	var typed rune
+	if keyMsg.Type == tea.KeyRunes && !keyMsg.Paste && len(keyMsg.Runes) == 1 {
+		typed = keyMsg.Runes[0]
+	} else if keyMsg.Type == tea.KeySpace {
+		typed = ' '
+	}
+	if typed == 0 || e.typed == 0 || (e.typed == ' ' && typed != ' ') {
+		e.undo = pushState(e.undo, before)
+	}
+	e.redo = nil
+	e.typed = typed
+	e.render()
+	return e, cmd
+}
+
+// view renders the editor and preview side by side between a header and a
+// help line.
+func (e slideEditor) view(width, height int, err error) string {
+	editorWidth, previewWidth := paneWidths(width)
+	bodyHeight := max(height-3, 1)
+
+	previewLines := strings.Split(strings.TrimRight(e.preview, "\n"), "\n")
+	preview := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(lipgloss.Color("#1E3A8A")).
+		Width(previewWidth - 1).
+		MaxWidth(previewWidth).
+		Height(bodyHeight).
+		MaxHeight(bodyHeight).
+		Render(strings.Join(previewLines, "\n"))
+	body := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(editorWidth).MaxHeight(bodyHeight).Render(e.textarea.View()),
+		preview)
+
+	pathLabel := e.base.path
+	if pathLabel == "" {
+		pathLabel = "unsaved slide"
+	} else if e.base.section >= 0 {
+		pathLabel = fmt.Sprintf("%s (section %d)", filepath.Base(pathLabel), e.base.section+1)
+	} else {
+		pathLabel = filepath.Base(pathLabel)
+	}
+	if e.dirty() {
+		pathLabel += " [modified]"
+	}
+
+	helpLines := []string{pathLabel, "esc close - ctrl+s save & exit - ctrl+z/ctrl+y undo/redo - ctrl+f find"}
+	switch {
+	case e.confirmDiscard:
+		helpLines = []string{pathLabel, "unsaved changes: esc again to discard, ctrl+s to save"}
+	case e.searching:
+		helpLines = []string{e.search.View(), "enter next match - esc back to editing"}
+	}
+	if e.message != "" {
+		helpLines = append(helpLines, e.message)
+	}
+	if err != nil {
+		helpLines = append(helpLines, fmt.Sprintf("error: %v", err))
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		Background(lipgloss.Color("#000000")).
+		Width(width).
+		MaxHeight(1).
+		Align(lipgloss.Left)
+	if e.confirmDiscard {
+		helpStyle = helpStyle.Foreground(lipgloss.Color("#FFFFFF")).Background(lipgloss.Color("#B91C1C"))
+	}
+	helpText := helpStyle.Render(strings.Join(helpLines, " | "))
+
+	statusBar := lipgloss.NewStyle().
+		Background(lipgloss.Color("#1E3A8A")).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Width(width).
+		Padding(0, 1).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(editorWidth-1).Render("EDIT MODE"),
+			"PREVIEW"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, statusBar, body, helpText)
+}
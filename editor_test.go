@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorKeys turns s into the keys typing it: '<' is ctrl+z, '>' ctrl+y,
+// '\b' backspace and '\n' enter.
+func editorKeys(s string) []tea.KeyMsg {
+	var keys []tea.KeyMsg
+	for _, r := range s {
+		switch r {
+		case '<':
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyCtrlZ})
+		case '>':
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyCtrlY})
+		case '\b':
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyBackspace})
+		case '\n':
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyEnter})
+		case ' ':
+			keys = append(keys, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		default:
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	return keys
+}
+
+func typeInto(e slideEditor, s string) slideEditor {
+	for _, key := range editorKeys(s) {
+		e, _ = e.update(key)
+	}
+	return e
+}
+
+func TestEditorUndoRedo(t *testing.T) {
+	tests := []struct {
+		name, keys, want, message string
+	}{
+		{"a word at a time", "hello world<", "hello ", ""},
+		{"back to the start", "hello world<<", "", ""},
+		{"nothing left", "hello world<<<", "", "nothing to undo"},
+		{"new lines are their own edit", "ab\ncd<<", "ab", ""},
+		{"backspaces are their own edit", "abc\b\b<", "ab", ""},
+		{"redo", "hello world<<>", "hello ", ""},
+		{"redo everything", "hello world<<>>", "hello world", ""},
+		{"nothing to redo", "hello world<>>", "hello world", "nothing to redo"},
+		{"an edit drops the redo", "ab<c>", "c", "nothing to redo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSlideEditor(slide{}, 0, "dark", 80, 24)
+			e = typeInto(e, tt.keys)
+			if got := e.textarea.Value(); got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+			if e.message != tt.message {
+				t.Errorf("message = %q, want %q", e.message, tt.message)
+			}
+			if e.dirty() != (tt.want != "") {
+				t.Errorf("dirty = %v with %q", e.dirty(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorUndoAcrossSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deck.md")
+	if err := os.WriteFile(path, []byte("# One\n---\n# Two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	slides, cfg, err := readDeck(filepath.Dir(path), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newStaticModel(filepath.Dir(path), path, slides, cfg, 80, 24)
+	press := func(keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			next, _ := m.update(key)
+			m = next.(model)
+		}
+	}
+
+	// A rejected save keeps the history, so the edit can be undone
+	press(editorKeys("e---\n")...)
+	press(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if !m.showEditor {
+		t.Fatal("a slide with a separator was saved")
+	}
+	press(editorKeys("<<")...)
+	if got := m.editor.textarea.Value(); got != "# One\n" || m.editor.dirty() {
+		t.Errorf("after undoing the rejected edit the editor holds %q", got)
+	}
+
+	// A saved edit is the new starting point
+	press(editorKeys("x")...)
+	press(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if got, _ := os.ReadFile(path); string(got) != "x# One\n---\n# Two\n" {
+		t.Fatalf("saved deck is %q", got)
+	}
+	press(editorKeys("e<")...)
+	if m.editor.message != "nothing to undo" || m.editor.textarea.Value() != "x# One\n" || m.editor.dirty() {
+		t.Errorf("reopened editor: message %q, value %q", m.editor.message, m.editor.textarea.Value())
+	}
+}
+
+func TestEditorFindNext(t *testing.T) {
+	type stop struct {
+		row, col int
+		message  string
+	}
+	tests := []struct {
+		name, content, query string
+		want                 []stop
+	}{
+		{"wraps around", "foo\nbar foo\nFOO", "foo", []stop{
+			{1, 4, "match 2 of 3"},
+			{2, 0, "match 3 of 3"},
+			{0, 0, "match 1 of 3"},
+			{1, 4, "match 2 of 3"},
+		}},
+		{"single match", "one two", "two", []stop{
+			{0, 4, "match 1 of 1"},
+			{0, 4, "match 1 of 1"},
+		}},
+		{"wide runes", "café\ncafé", "É", []stop{
+			{0, 3, "match 1 of 2"},
+			{1, 3, "match 2 of 2"},
+			{0, 3, "match 1 of 2"},
+		}},
+		{"no match", "foo", "bar", []stop{
+			{0, 0, "no match"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSlideEditor(slide{raw: tt.content}, 0, "dark", 80, 24)
+			e, _ = e.update(tea.KeyMsg{Type: tea.KeyCtrlF})
+			e, _ = e.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.query)})
+			for i, want := range tt.want {
+				e, _ = e.update(tea.KeyMsg{Type: tea.KeyEnter})
+				row, col := e.position()
+				if row != want.row || col != want.col || e.message != want.message {
+					t.Errorf("find %d: at %d:%d %q, want %d:%d %q", i+1, row, col, e.message, want.row, want.col, want.message)
+				}
+			}
+			if e.dirty() {
+				t.Error("finding changed the slide")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runExport implements `slidetty export [flags] [deck]`.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: slidetty export [flags] [deck]")
+		fs.PrintDefaults()
+	}
+	htmlPath := fs.String("html", "", "write the deck as a self-contained HTML file")
+	pdfPath := fs.String("pdf", "", "write the terminal rendering as a PDF, one page per slide")
+	svgDir := fs.String("svg", "", "write the terminal rendering of each slide as an SVG into this directory")
+	pngDir := fs.String("png", "", "write the terminal rendering of each slide as a PNG into this directory")
+	width := fs.Int("width", 100, "terminal width in columns for --pdf, --svg and --png")
+	height := fs.Int("height", 30, "terminal height in rows for --pdf, --svg and --png")
+	steps := fs.Bool("steps", false, "emit one page or image per reveal step instead of per slide")
+	fs.Parse(args)
+
+	if *htmlPath == "" && *pdfPath == "" && *svgDir == "" && *pngDir == "" {
+		fs.Usage()
+		return fmt.Errorf("nothing to export; pass --html, --pdf, --svg or --png")
+	}
+
+	root, deckFile, err := resolveDeck(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	slides, cfg, err := readDeck(root, deckFile)
+	if err != nil {
+		return err
+	}
+	if len(slides) == 0 {
+		return fmt.Errorf("no slides found in %s", root)
+	}
+
+	if *htmlPath != "" {
+		if err := exportHTML(slides, cfg, *htmlPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Exported %d slides to %s\n", len(slides), *htmlPath)
+	}
+
+	if *pdfPath == "" && *svgDir == "" && *pngDir == "" {
+		return nil
+	}
+	m := newStaticModel(root, deckFile, slides, cfg, *width, *height)
+	frames := terminalFrames(m, *steps)
+	page := themePage(m.config.themeName())
+
+	if *pdfPath != "" {
+		screens := make([]*screen, len(frames))
+		for i, f := range frames {
+			screens[i] = parseScreen(f.output, *width, *height, page)
+		}
+		if err := os.MkdirAll(filepath.Dir(*pdfPath), 0o755); err != nil {
+			return err
+		}
+		if err := writePDF(*pdfPath, screens); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Exported %d pages to %s\n", len(frames), *pdfPath)
+	}
+
+	if *svgDir != "" {
+		if err := os.MkdirAll(*svgDir, 0o755); err != nil {
+			return err
+		}
+		for _, f := range frames {
+			path := filepath.Join(*svgDir, f.name+".svg")
+			if err := os.WriteFile(path, []byte(renderSVG(parseScreen(f.output, *width, *height, page))), 0o644); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(os.Stdout, "Exported %d images to %s\n", len(frames), *svgDir)
+	}
+
+	if *pngDir != "" {
+		if err := os.MkdirAll(*pngDir, 0o755); err != nil {
+			return err
+		}
+		fonts, err := loadPNGFonts(14)
+		if err != nil {
+			return err
+		}
+		for _, f := range frames {
+			path := filepath.Join(*pngDir, f.name+".png")
+			if err := writePNG(path, renderPNG(parseScreen(f.output, *width, *height, page), fonts)); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(os.Stdout, "Exported %d images to %s\n", len(frames), *pngDir)
+	}
+	return nil
+}
+
+// terminalFrame is one rendered View of the deck.
+type terminalFrame struct {
+	name   string // file name stem, e.g. slide-03 or slide-03-2
+	output string
+}
+
+// terminalFrames renders every slide, or every reveal step of every slide
+// when perStep is set. Without perStep slides are shown fully revealed.
+func terminalFrames(m model, perStep bool) []terminalFrame {
+	var frames []terminalFrame
+	for i, s := range m.slides {
+		steps := revealSteps(s)
+		if !perStep {
+			steps = steps[len(steps)-1:]
+		}
+		for _, step := range steps {
+			name := fmt.Sprintf("slide-%02d", i+1)
+			if perStep && len(steps) > 1 {
+				name += fmt.Sprintf("-%d", step)
+			}
+			frames = append(frames, terminalFrame{name: name, output: m.frame(i, step)})
+		}
+	}
+	return frames
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// externalEditorClosedMsg reports that the editor opened on a slide exited.
+type externalEditorClosedMsg struct {
+	slideIndex int
+	path       string
+	section    int
+	err        error
+}
+
+// waitFlags are the flags that keep GUI editors from returning as soon as
+// they have handed the file to an open window, before it is edited.
+var waitFlags = map[string][]string{
+	"code":   {"--wait", "-w"},
+	"codium": {"--wait", "-w"},
+	"cursor": {"--wait", "-w"},
+	"subl":   {"--wait", "-w"},
+	"zed":    {"--wait", "-w"},
+}
+
+// editorArgs returns the command line opening path at line in the user's
+// $VISUAL or $EDITOR, falling back to vi. Editors that take file:line are
+// told the line that way, the rest with the +line argument vi, nano and
+// emacs understand. GUI editors are told to wait for the file to be closed.
+func editorArgs(path string, line int) []string {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	if flags, ok := waitFlags[filepath.Base(args[0])]; ok && !slices.ContainsFunc(args[1:], func(arg string) bool { return slices.Contains(flags, arg) }) {
+		args = append(args, flags[0])
+	}
+	switch filepath.Base(args[0]) {
+	case "code", "codium", "cursor":
+		return append(args, "--goto", fmt.Sprintf("%s:%d", path, line))
+	case "hx", "helix", "subl", "zed", "micro":
+		return append(args, fmt.Sprintf("%s:%d", path, line))
+	default:
+		return append(args, fmt.Sprintf("+%d", line), path)
+	}
+}
+
+// openInEditor suspends the presentation and edits the slide at slideIndex
+// in an external editor, starting at the slide's first line.
+func openInEditor(slideIndex int, path string, section int) tea.Cmd {
+	args := editorArgs(path, sectionLine(path, section))
+	cmd := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return externalEditorClosedMsg{slideIndex: slideIndex, path: path, section: section, err: err}
+	})
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorArgs(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   []string
+	}{
+		{"vim", []string{"vim", "+3", "deck.md"}},
+		{"code", []string{"code", "--wait", "--goto", "deck.md:3"}},
+		{"code -w", []string{"code", "-w", "--goto", "deck.md:3"}},
+		{"/usr/local/bin/subl", []string{"/usr/local/bin/subl", "--wait", "deck.md:3"}},
+		{"hx", []string{"hx", "deck.md:3"}},
+	}
+	for _, tt := range tests {
+		t.Setenv("VISUAL", tt.editor)
+		if got := editorArgs("deck.md", 3); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("editorArgs with %q = %q, want %q", tt.editor, got, tt.want)
+		}
+	}
+}
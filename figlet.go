@@ -0,0 +1,307 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// defaultBigFont draws "# !big" headings that don't name a font.
+const defaultBigFont = "block"
+
+//go:embed fonts/*.flf
+var bundledFonts embed.FS
+
+// bigHeadingRe matches a heading to draw in big letters, such as
+// "# !big Title" or "## !big:braille Title".
+var bigHeadingRe = regexp.MustCompile(`^#{1,6}\s+!big(?::(\S+))?\s+(.*)$`)
+
+// figFont is a FIGlet font. Glyphs are kept whole-width; FIGlet's kerning
+// and smushing rules are not applied.
+type figFont struct {
+	height int
+	glyphs map[rune][]string
+}
+
+// parseFIGletFont reads a font in the FIGlet .flf format.
+func parseFIGletFont(content string) (*figFont, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	header := strings.Fields(lines[0])
+	if len(header) < 6 || !strings.HasPrefix(header[0], "flf2a") || len(header[0]) < 6 {
+		return nil, fmt.Errorf("not a FIGlet font")
+	}
+	hardblank := []rune(header[0])[5]
+	height, err := strconv.Atoi(header[1])
+	if err != nil || height < 1 {
+		return nil, fmt.Errorf("bad FIGlet font height %q", header[1])
+	}
+	comments, err := strconv.Atoi(header[5])
+	if err != nil {
+		return nil, fmt.Errorf("bad FIGlet comment line count %q", header[5])
+	}
+
+	f := &figFont{height: height, glyphs: make(map[rune][]string)}
+	// The printable ASCII characters come first, then the seven German
+	// ones; code-tagged characters that may follow are not read
+	codes := []rune{}
+	for r := rune(32); r < 127; r++ {
+		codes = append(codes, r)
+	}
+	codes = append(codes, 'Ä', 'Ö', 'Ü', 'ä', 'ö', 'ü', 'ß')
+	next := 1 + comments
+	for _, code := range codes {
+		if next+height > len(lines) {
+			break
+		}
+		glyph := make([]string, height)
+		for i, line := range lines[next : next+height] {
+			// Each row ends with one or two endmarks, the row's last character
+			line = strings.TrimRight(line, " ")
+			if line != "" {
+				endmark := line[len(line)-1:]
+				line = strings.TrimSuffix(strings.TrimSuffix(line, endmark), endmark)
+			}
+			glyph[i] = strings.ReplaceAll(line, string(hardblank), " ")
+		}
+		f.glyphs[code] = glyph
+		next += height
+	}
+	if len(f.glyphs) == 0 {
+		return nil, fmt.Errorf("FIGlet font has no characters")
+	}
+	return f, nil
+}
+
+// render draws text one glyph after another. Characters the font lacks are
+// left out.
+func (f *figFont) render(text string) []string {
+	rows := make([]string, f.height)
+	for _, r := range text {
+		glyph, ok := f.glyphs[r]
+		if !ok {
+			continue
+		}
+		width := 0
+		for _, row := range glyph {
+			width = max(width, len([]rune(row)))
+		}
+		for i, row := range glyph {
+			rows[i] += row + strings.Repeat(" ", width-len([]rune(row)))
+		}
+	}
+	for i := range rows {
+		rows[i] = strings.TrimRight(rows[i], " ")
+	}
+	return rows
+}
+
+// cachedFont is a parsed font and the modification time of the file it
+// was read from, zero for bundled fonts.
+type cachedFont struct {
+	modTime time.Time
+	font    *figFont
+}
+
+var (
+	fontsMu sync.Mutex
+	fonts   = make(map[string]cachedFont) // by path
+)
+
+// loadFIGletFont loads a bundled font by name, or a .flf file by path
+// relative to dir. A deck's font file is read again once it changes.
+func loadFIGletFont(name, dir string) (*figFont, error) {
+	var path string
+	var modTime time.Time
+	var read func() ([]byte, error)
+	if strings.HasSuffix(name, ".flf") {
+		path = name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if info, err := os.Stat(path); err == nil {
+			modTime = info.ModTime()
+		}
+		read = func() ([]byte, error) { return os.ReadFile(path) }
+	} else {
+		path = "fonts/" + name + ".flf"
+		read = func() ([]byte, error) { return bundledFonts.ReadFile(path) }
+	}
+
+	fontsMu.Lock()
+	defer fontsMu.Unlock()
+	if cached, ok := fonts[path]; ok && cached.modTime.Equal(modTime) {
+		return cached.font, nil
+	}
+	content, err := read()
+	if err != nil {
+		return nil, fmt.Errorf("unknown font %q", name)
+	}
+	f, err := parseFIGletFont(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	fonts[path] = cachedFont{modTime: modTime, font: f}
+	return f, nil
+}
+
+// rasterize draws text in the 7x13 bitmap font, trimmed to the rows any
+// letter uses.
+func rasterize(text string) *image.Alpha {
+	img := image.NewAlpha(image.Rect(0, 0, 7*len([]rune(text)), 13))
+	d := font.Drawer{Dst: img, Src: image.Opaque, Face: basicfont.Face7x13, Dot: fixed.P(0, 11)}
+	d.DrawString(text)
+
+	top, bottom := img.Bounds().Max.Y, -1
+	for y := 0; y < img.Bounds().Max.Y; y++ {
+		for x := 0; x < img.Bounds().Max.X; x++ {
+			if img.AlphaAt(x, y).A > 0 {
+				top, bottom = min(top, y), max(bottom, y)
+			}
+		}
+	}
+	if bottom < 0 {
+		return image.NewAlpha(image.Rect(0, 0, img.Bounds().Max.X, 0))
+	}
+	return img.SubImage(image.Rect(0, top, img.Bounds().Max.X, bottom+1)).(*image.Alpha)
+}
+
+// dotsText turns img into text, each character covering a cellWidth by
+// cellHeight block of pixels. glyph receives the set pixels of a block as
+// bits in row-major order.
+func dotsText(img *image.Alpha, cellWidth, cellHeight int, glyph func(bits int) rune) []string {
+	b := img.Bounds()
+	var rows []string
+	for y := b.Min.Y; y < b.Max.Y; y += cellHeight {
+		var row []rune
+		for x := b.Min.X; x < b.Max.X; x += cellWidth {
+			bits := 0
+			for dy := 0; dy < cellHeight; dy++ {
+				for dx := 0; dx < cellWidth; dx++ {
+					if img.AlphaAt(x+dx, y+dy).A > 0 && y+dy < b.Max.Y {
+						bits |= 1 << (dy*cellWidth + dx)
+					}
+				}
+			}
+			row = append(row, glyph(bits))
+		}
+		rows = append(rows, strings.TrimRight(string(row), " ⠀"))
+	}
+	return rows
+}
+
+// halfBlockText draws text with a pixel per half character cell.
+func halfBlockText(text string) []string {
+	return dotsText(rasterize(text), 1, 2, func(bits int) rune {
+		return []rune(" ▀▄█")[bits]
+	})
+}
+
+// brailleText draws text with a pixel per braille dot, two by four to a
+// character cell.
+func brailleText(text string) []string {
+	// Braille dot numbers for each pixel of a 2x4 block, in row-major order
+	dots := []int{0, 3, 1, 4, 2, 5, 6, 7}
+	return dotsText(rasterize(text), 2, 4, func(bits int) rune {
+		r := 0
+		for i, dot := range dots {
+			if bits&(1<<i) != 0 {
+				r |= 1 << dot
+			}
+		}
+		return rune(0x2800 + r)
+	})
+}
+
+// bigText draws text in the named font: "halfblock", "braille", a bundled
+// FIGlet font, or a .flf file relative to dir.
+func bigText(text, fontName, dir string) ([]string, error) {
+	switch fontName {
+	case "halfblock":
+		return halfBlockText(text), nil
+	case "braille":
+		return brailleText(text), nil
+	}
+	f, err := loadFIGletFont(fontName, dir)
+	if err != nil {
+		return nil, err
+	}
+	return f.render(text), nil
+}
+
+// bigTextBlock returns text drawn in fontName as a fenced code block, so
+// glamour shows it as it is.
+func bigTextBlock(texts []string, fontName, dir string) []string {
+	if fontName == "" {
+		fontName = defaultBigFont
+	}
+	block := []string{"```"}
+	for _, text := range texts {
+		lines, err := bigText(strings.TrimSpace(text), fontName, dir)
+		if err != nil {
+			lines = []string{"big text: " + err.Error()}
+		}
+		block = append(block, lines...)
+	}
+	return append(block, "```")
+}
+
+// lineSplice replaces lines start to end, inclusive, of a slide with lines.
+type lineSplice struct {
+	start, end int
+	lines      []string
+}
+
+// findBigText returns the "# !big Title" headings and ```figlet fences in
+// lines, each with the text drawn in big letters. A fence's info string may
+// name the font, as in ```figlet braille. Fonts given as .flf paths are
+// found relative to dir.
+func findBigText(lines []string, dir string) []lineSplice {
+	var found []lineSplice
+	var fence codeFence
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if char, _, info := fenceMarker(line); !fence.inside() && char == '`' && strings.HasPrefix(info, "figlet") {
+			fontName := strings.TrimSpace(strings.TrimPrefix(info, "figlet"))
+			block := openFence(line)
+			start := i
+			var texts []string
+			for i++; i < len(lines) && !block.closes(lines[i]); i++ {
+				texts = append(texts, lines[i])
+			}
+			found = append(found, lineSplice{start: start, end: min(i, len(lines)-1), lines: bigTextBlock(texts, fontName, dir)})
+			continue
+		}
+		fence.step(line)
+		if match := bigHeadingRe.FindStringSubmatch(strings.TrimSpace(line)); !fence.inside() && match != nil {
+			found = append(found, lineSplice{start: i, end: i, lines: bigTextBlock([]string{match[2]}, match[1], dir)})
+		}
+	}
+	return found
+}
+
+// expandBigText replaces the big text findBigText finds in markdown with
+// the text drawn.
+func expandBigText(markdown, dir string) string {
+	if !strings.Contains(markdown, "!big") && !strings.Contains(markdown, "figlet") {
+		return markdown
+	}
+	lines := strings.Split(markdown, "\n")
+	var out []string
+	next := 0
+	for _, big := range findBigText(lines, dir) {
+		out = append(append(out, lines[next:big.start]...), big.lines...)
+		next = big.end + 1
+	}
+	return strings.Join(append(out, lines[next:]...), "\n")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandBigText(t *testing.T) {
+	out := expandBigText("# !big Hi\n\ntext", ".")
+	lines := strings.Split(out, "\n")
+	if lines[0] != "```" || strings.Contains(out, "!big") || !strings.HasSuffix(out, "```\n\ntext") {
+		t.Errorf("expandBigText drew\n%s", out)
+	}
+	if len(lines) < 5 {
+		t.Errorf("big text is %d lines, want the font's height", len(lines)-4)
+	}
+
+	braille := expandBigText("## !big:braille Hi", ".")
+	if !strings.ContainsAny(braille, "⠁⠂⠄⡀⠈⠐⠠⢀⣿") {
+		t.Errorf("braille font drew\n%s", braille)
+	}
+
+	figlet := expandBigText("```figlet halfblock\nA\nB\n```", ".")
+	if strings.Contains(figlet, "figlet") || strings.Count(figlet, "```") != 2 {
+		t.Errorf("figlet fence drew\n%s", figlet)
+	}
+}
+
+func TestExpandBigTextLeavesCodeAlone(t *testing.T) {
+	for _, markdown := range []string{
+		"```md\n# !big Not drawn\n```",
+		"# Regular heading",
+		"Mentions !big in passing",
+	} {
+		if got := expandBigText(markdown, "."); got != markdown {
+			t.Errorf("expandBigText(%q) = %q", markdown, got)
+		}
+	}
+}
+
+func TestExpandBigTextUnknownFont(t *testing.T) {
+	if out := expandBigText("# !big:nosuch Hi", "."); !strings.Contains(out, "big text:") {
+		t.Errorf("unknown font drew\n%s", out)
+	}
+}
+
+func TestLoadFIGletFontRereadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tiny.flf")
+	// writeFont writes a one-row font drawing each character repeat times
+	writeFont := func(repeat int, modTime time.Time) {
+		t.Helper()
+		lines := []string{"flf2a$ 1 1 10 0 0"}
+		for r := rune(32); r < 127; r++ {
+			lines = append(lines, strings.Repeat(string(r), repeat)+"@@")
+		}
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	glyph := func() string {
+		t.Helper()
+		f, err := loadFIGletFont("tiny.flf", dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(f.glyphs['A'], "\n")
+	}
+
+	start := time.Now().Add(-time.Hour)
+	writeFont(1, start)
+	if got := glyph(); got != "A" {
+		t.Fatalf("glyph = %q, want %q", got, "A")
+	}
+	writeFont(2, start.Add(time.Minute))
+	if got := glyph(); got != "AA" {
+		t.Errorf("after the font changed the glyph is %q, want %q", got, "AA")
+	}
+	if got := glyph(); got != "AA" {
+		t.Errorf("cached glyph = %q, want %q", got, "AA")
+	}
+}
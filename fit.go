@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// fitCache remembers the wrap width fit mode chose for each slide, and a
+// renderer for each width, so View does not search again on every frame.
+type fitCache struct {
+	theme     string
+	renderers map[int]*glamour.TermRenderer
+	widths    map[string]int // by slide content and area size
+}
+
+func newFitCache() *fitCache {
+	return &fitCache{renderers: make(map[int]*glamour.TermRenderer), widths: make(map[string]int)}
+}
+
+// renderer returns a renderer for theme wrapping at width.
+func (c *fitCache) renderer(theme string, width int) *glamour.TermRenderer {
+	if c == nil {
+		return newRenderer(theme, width)
+	}
+	if c.theme != theme {
+		c.theme = theme
+		c.renderers = make(map[int]*glamour.TermRenderer)
+	}
+	r, ok := c.renderers[width]
+	if !ok {
+		r = newRenderer(theme, width)
+		c.renderers[width] = r
+	}
+	return r
+}
+
+// fits reports whether the deck is in fit mode, which is off unless set.
+func (c deckConfig) fits() bool {
+	return c.Fit != nil && *c.Fit
+}
+
+// centersHorizontally and centersVertically read the deck's center setting;
+// slide layouts can center slides too.
+func (c deckConfig) centersHorizontally() bool {
+	return c.Center == "horizontal" || c.Center == "both"
+}
+
+func (c deckConfig) centersVertically() bool {
+	return c.Center == "vertical" || c.Center == "both"
+}
+
+// fitWidth picks the wrap width for slide index in fit mode: the narrowest
+// at which the fully revealed slide fits the content height, so the slide
+// fills the screen top to bottom rather than in a few long lines. Widths
+// stay above half the usual wrap width to keep lines readable, and a slide
+// too tall at any width gets the usual one.
+func (m model) fitWidth(index int) int {
+	widest := m.wrapWidth()
+	narrowest := min(max(widest/2, 20), widest)
+	height := m.contentHeight(index)
+	s := m.slides[index]
+	markdown := slideMarkdown(s, s.reveal.totalItems())
+
+	key := fmt.Sprintf("%s\x00%d\x00%d", markdown, widest, height)
+	if m.fit != nil {
+		if width, ok := m.fit.widths[key]; ok {
+			return width
+		}
+	}
+
+	fits := func(width int) bool {
+		rendered, err := m.fit.renderer(m.config.themeName(), width).Render(markdown)
+		return err == nil && len(trimBlankLines(strings.Split(rendered, "\n"))) <= height
+	}
+	width := widest
+	if fits(widest) {
+		// Fewer lines fit as the width shrinks, so search for the boundary
+		lo, hi := narrowest, widest
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if fits(mid) {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		width = lo
+	}
+	if m.fit != nil {
+		m.fit.widths[key] = width
+	}
+	return width
+}
+
+// trimBlankLines drops the blank lines glamour puts around a document.
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(ansi.Strip(lines[0])) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(ansi.Strip(lines[len(lines)-1])) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// centerLines moves lines right so the block they form is centered in
+// width columns. Glamour pads every line to the wrap width, so the block's
+// width is measured without trailing spaces and the padding cut off.
+func centerLines(lines []string, width int) []string {
+	block := 0
+	for _, line := range lines {
+		block = max(block, ansi.StringWidth(strings.TrimRight(ansi.Strip(line), " ")))
+	}
+	// Glamour's left margin is part of the block; balance it on the right
+	margin := (width - block - 2) / 2
+	if margin <= 0 {
+		return lines
+	}
+	centered := make([]string, len(lines))
+	pad := strings.Repeat(" ", margin)
+	for i, line := range lines {
+		centered[i] = pad + ansi.Truncate(line, block, "")
+	}
+	return centered
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestFitCentersNarrowSlides(t *testing.T) {
+	content := "# Short\n\nA slide with a few words on it.\n"
+	slides := []slide{newSlide(content, content, slideMeta{}, "01.md", -1)}
+	m := newStaticModel(".", "", slides, deckConfig{Fit: setting(true)}, 120, 30)
+
+	if width := m.fitWidth(0); width >= m.wrapWidth() {
+		t.Fatalf("fitWidth = %d, want narrower than %d", width, m.wrapWidth())
+	}
+	for _, line := range m.slideLines(0) {
+		text := ansi.Strip(line)
+		if strings.Contains(text, "A slide") {
+			left := len(text) - len(strings.TrimLeft(text, " "))
+			right := 120 - ansi.StringWidth(strings.TrimRight(text, " "))
+			if left < right/2 {
+				t.Errorf("fitted slide isn't centered: %d columns on the left, %d on the right\n%q", left, right, text)
+			}
+			return
+		}
+	}
+	t.Fatal("slide text not found")
+}
+
+func TestFitCentersShortSlidesVertically(t *testing.T) {
+	content := "# Short\n\nA slide with a few words on it.\n"
+	slides := []slide{newSlide(content, content, slideMeta{}, "01.md", -1)}
+	m := newStaticModel(".", "", slides, deckConfig{Fit: setting(true)}, 120, 30)
+
+	height := m.contentHeight(0)
+	lines, _ := m.slideArea(0, height)
+	first, last := -1, -1
+	for i, line := range lines {
+		if strings.TrimSpace(ansi.Strip(line)) != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		t.Fatal("slide text not found")
+	}
+	above, below := first, height-1-last
+	if above < below-1 || above > below+1 {
+		t.Errorf("fitted slide isn't centered top to bottom: %d lines above, %d below", above, below)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// highlightFenceRe matches a code fence whose info string ends in groups of
+// lines to highlight, as in ```go {3-5|8|10-12}.
+var highlightFenceRe = regexp.MustCompile("^(\\s*(?:```+|~~~+)[^{]*?)\\s*\\{([0-9,| -]+)\\}\\s*$")
+
+// codeHighlight is one group of lines stepped through in a code block.
+type codeHighlight struct {
+	fence int          // line of the code block's opening fence
+	lines map[int]bool // lines of code highlighted, counting from 1
+	step  int          // reveal step the group is highlighted from
+}
+
+// parseHighlightGroups reads groups such as "3-5|8|10-12" for a code block
+// of size lines. Each group is a comma-separated list of lines and ranges;
+// groups are separated by "|". Lines outside the block are left out.
+func parseHighlightGroups(spec string, size int) []map[int]bool {
+	var groups []map[int]bool
+	for _, group := range strings.Split(spec, "|") {
+		lines := make(map[int]bool)
+		for _, part := range strings.Split(group, ",") {
+			from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+			first, err := strconv.Atoi(strings.TrimSpace(from))
+			if err != nil {
+				continue
+			}
+			last := first
+			if isRange {
+				if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+					continue
+				}
+			}
+			for n := max(first, 1); n <= min(last, size); n++ {
+				lines[n] = true
+			}
+		}
+		if len(lines) > 0 {
+			groups = append(groups, lines)
+		}
+	}
+	return groups
+}
+
+// highlightFragments returns a fragment for each highlight group of the
+// code block whose opening fence is lines[fence], if it has any.
+func highlightFragments(lines []string, fence int) []revealFragment {
+	match := highlightFenceRe.FindStringSubmatch(lines[fence])
+	if match == nil {
+		return nil
+	}
+	block, size := openFence(lines[fence]), 0
+	for i := fence + 1; i < len(lines) && !block.closes(lines[i]); i++ {
+		size++
+	}
+	var fragments []revealFragment
+	for _, group := range parseHighlightGroups(match[2], size) {
+		fragments = append(fragments, revealFragment{
+			immediate: true,
+			highlight: &codeHighlight{fence: fence, lines: group},
+		})
+	}
+	return fragments
+}
+
+// highlightCode returns which lines to dim at reveal step visible: in each
+// code block stepped through, the lines outside its current group. Blocks
+// whose first group is still to come are left alone.
+func highlightCode(lines []string, highlights []codeHighlight, visible int) map[int]bool {
+	current := make(map[int]codeHighlight)
+	for _, h := range highlights {
+		if h.step <= visible {
+			current[h.fence] = h
+		}
+	}
+	dim := make(map[int]bool)
+	for fence, h := range current {
+		block := openFence(lines[fence])
+		for i, n := fence+1, 1; i < len(lines) && !block.closes(lines[i]); i, n = i+1, n+1 {
+			if !h.lines[n] {
+				dim[i] = true
+			}
+		}
+	}
+	return dim
+}
+
+// stripHighlightGroups removes the highlight groups from a fence line, so
+// the info string names only the language.
+func stripHighlightGroups(line string) string {
+	if match := highlightFenceRe.FindStringSubmatch(line); match != nil {
+		return match[1]
+	}
+	return line
+}
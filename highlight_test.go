@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHighlightGroups(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []map[int]bool
+	}{
+		{"3", []map[int]bool{{3: true}}},
+		{"3-5|8", []map[int]bool{{3: true, 4: true, 5: true}, {8: true}}},
+		{"1,3-4 | 7", []map[int]bool{{1: true, 3: true, 4: true}, {7: true}}},
+		{"2||x|5-x", []map[int]bool{{2: true}}},
+		{"", nil},
+		{"0-2|9-12|20", []map[int]bool{{1: true, 2: true}, {9: true, 10: true}}},
+	}
+	for _, tt := range tests {
+		if got := parseHighlightGroups(tt.spec, 10); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseHighlightGroups(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestStripHighlightGroups(t *testing.T) {
+	if got := stripHighlightGroups("```go {3-5|8}"); got != "```go" {
+		t.Errorf("stripHighlightGroups = %q", got)
+	}
+	if got := stripHighlightGroups("```go"); got != "```go" {
+		t.Errorf("stripHighlightGroups = %q", got)
+	}
+}
+
+func TestHighlightHugeRange(t *testing.T) {
+	lines := []string{"```go {1-1000000000|2}", "a", "b", "```"}
+	done := make(chan []revealFragment)
+	go func() { done <- highlightFragments(lines, 0) }()
+	select {
+	case fragments := <-done:
+		if len(fragments) != 2 || len(fragments[0].highlight.lines) != 2 {
+			t.Errorf("highlightFragments = %+v", fragments)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a huge range took more than a second")
+	}
+}
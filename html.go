@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// blankOut replaces every match of re with as many newlines as it spanned,
+// so line numbers computed on the original content stay valid.
+func blankOut(re *regexp.Regexp, content string) string {
+	return re.ReplaceAllStringFunc(content, func(match string) string {
+		return strings.Repeat("\n", strings.Count(match, "\n"))
+	})
+}
+
+// htmlMarkdown prepares a slide for HTML export: the same markdown the
+// terminal renderer sees after stripCommandBlocks and stripNotes, with big
+// text drawn and code morphs in their final state. It also returns, for
+// each line, the line of the slide it comes from, so reveal items can be
+// found by line number.
+func htmlMarkdown(s slide) (string, []int) {
+	lines := strings.Split(s.content, "\n")
+	for _, idx := range s.reveal.directiveLines {
+		if idx < len(lines) {
+			lines[idx] = ""
+		}
+	}
+	// Columns are shown one after another
+	var fence codeFence
+	for i, line := range lines {
+		fence.step(line)
+		if !fence.inside() && (strings.HasPrefix(strings.TrimSpace(line), ":::") || strings.TrimSpace(line) == columnBreak) {
+			lines[i] = ""
+		}
+	}
+	content := strings.Join(lines, "\n")
+	content = blankOut(commandBlockRe, content)
+	content = blankOut(notesCommentRe, content)
+	lines = strings.Split(blankOut(notesBlockRe, content), "\n")
+
+	splices := make(map[int]lineSplice)
+	for _, big := range findBigText(lines, filepath.Dir(s.path)) {
+		splices[big.start] = big
+	}
+	for _, morph := range s.reveal.morphs {
+		splices[morph.start] = lineSplice{start: morph.start, end: morph.end, lines: morph.frame(1)}
+	}
+	var out []string
+	var origin []int
+	for i := 0; i < len(lines); i++ {
+		if splice, ok := splices[i]; ok {
+			for _, line := range splice.lines {
+				out = append(out, line)
+				origin = append(origin, i)
+			}
+			i = splice.end
+			continue
+		}
+		out = append(out, lines[i])
+		origin = append(origin, i)
+	}
+	return strings.Join(out, "\n"), origin
+}
+
+// markFragments tags the blocks reveal directives reveal, list items,
+// paragraphs, code blocks and the rest, and the groups of lines code blocks
+// step through, so the exported page can show them one step at a time, like
+// the terminal does. origin maps the lines of source to those of the slide.
+func markFragments(doc ast.Node, source []byte, origin []int, cfg revealConfig) {
+	steps := make(map[int]int)
+	for i, item := range cfg.items {
+		for _, line := range item {
+			steps[line] = i + 1
+		}
+	}
+	if len(steps) == 0 && len(cfg.highlights) == 0 {
+		return
+	}
+	// Groups as "step=line line;step=line", by the line of their fence
+	groups := make(map[int][]string)
+	for _, h := range cfg.highlights {
+		var numbers []int
+		for n := range h.lines {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var lines []string
+		for _, n := range numbers {
+			lines = append(lines, strconv.Itoa(n))
+		}
+		groups[h.fence] = append(groups[h.fence], fmt.Sprintf("%d=%s", h.step, strings.Join(lines, " ")))
+	}
+	// A block's first line also starts its first child; tag only the block
+	tagged := make(map[int]bool)
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock || n.Kind() == ast.KindDocument {
+			return ast.WalkContinue, nil
+		}
+		line, ok := firstLine(n, source)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		// A fenced block's first line follows its fence
+		if n.Kind() == ast.KindFencedCodeBlock && line > 0 {
+			if group, ok := groups[origin[line-1]]; ok {
+				n.SetAttributeString("data-highlight", []byte(strings.Join(group, ";")))
+			}
+		}
+		if tagged[line] {
+			return ast.WalkContinue, nil
+		}
+		if step, ok := steps[origin[line]]; ok && step > 1 {
+			tagged[line] = true
+			n.SetAttributeString("class", []byte("fragment"))
+			n.SetAttributeString("data-step", []byte(fmt.Sprint(step)))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// firstLine returns the source line n starts on, found through its first
+// descendant holding text.
+func firstLine(n ast.Node, source []byte) (int, bool) {
+	for ; n != nil; n = n.FirstChild() {
+		if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+			return bytes.Count(source[:n.Lines().At(0).Start], []byte("\n")), true
+		}
+	}
+	return 0, false
+}
+
+// inlineImages points the images in doc at data URIs holding the files they
+// name, found relative to dir, so the page needs nothing beside it. Images
+// that can't be read, or that browsers won't show from a data URI, keep
+// their paths.
+func inlineImages(doc ast.Node, dir string) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		img, ok := n.(*ast.Image)
+		if !entering || !ok || strings.Contains(string(img.Destination), "://") {
+			return ast.WalkContinue, nil
+		}
+		path := string(img.Destination)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return ast.WalkContinue, nil
+		}
+		switch kind := http.DetectContentType(data); kind {
+		case "image/png", "image/gif", "image/jpeg", "image/webp":
+			img.Destination = []byte("data:" + kind + ";base64," + base64.StdEncoding.EncodeToString(data))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// codeBlockRenderer renders fenced code blocks the way goldmark does, but
+// with their attributes, which goldmark leaves out, so that code blocks can
+// be reveal fragments too. Blocks stepping through highlighted lines get
+// each line in a span.
+type codeBlockRenderer struct{}
+
+func (codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, func(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		n := node.(*ast.FencedCodeBlock)
+		if !entering {
+			w.WriteString("</code></pre>\n")
+			return ast.WalkContinue, nil
+		}
+		w.WriteString("<pre")
+		if n.Attributes() != nil {
+			gmhtml.RenderAttributes(w, n, nil)
+		}
+		w.WriteString("><code")
+		if language := n.Language(source); language != nil {
+			fmt.Fprintf(w, " class=\"language-%s\"", html.EscapeString(string(language)))
+		}
+		w.WriteByte('>')
+		_, highlighted := n.AttributeString("data-highlight")
+		for i := 0; i < n.Lines().Len(); i++ {
+			segment := n.Lines().At(i)
+			line := html.EscapeString(string(segment.Value(source)))
+			if highlighted {
+				line = "<span class=\"line\">" + line + "</span>"
+			}
+			w.WriteString(line)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// renderSlideHTML renders one slide to an HTML <section>.
+func renderSlideHTML(md goldmark.Markdown, s slide, index int) (string, error) {
+	markdown, origin := htmlMarkdown(s)
+	source := []byte(markdown)
+	doc := md.Parser().Parse(text.NewReader(source))
+	markFragments(doc, source, origin, s.reveal)
+	inlineImages(doc, filepath.Dir(s.path))
+
+	var body bytes.Buffer
+	if err := md.Renderer().Render(&body, source, doc); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	class := "slide"
+	if s.dimsHidden() {
+		class += " dim"
+	}
+	fmt.Fprintf(&b, "<section class=\"%s\" data-steps=\"%d\" id=\"slide-%d\">\n", class, s.reveal.totalItems(), index+1)
+	b.WriteString(body.String())
+	if len(s.commands) > 0 {
+		b.WriteString("<div class=\"commands\">\n")
+		for _, command := range s.commands {
+			escaped := html.EscapeString(command)
+			fmt.Fprintf(&b, "<div class=\"command\"><button data-copy=\"%s\">copy</button><code>%s</code></div>\n", escaped, escaped)
+		}
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</section>\n")
+	return b.String(), nil
+}
+
+// exportHTML writes the deck as a single offline HTML page with keyboard
+// navigation, reveal fragments and copy buttons for command blocks.
+func exportHTML(slides []slide, cfg deckConfig, path string) error {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100))),
+	)
+
+	var sections strings.Builder
+	for i, s := range slides {
+		section, err := renderSlideHTML(md, s, i)
+		if err != nil {
+			return fmt.Errorf("slide %d: %v", i+1, err)
+		}
+		sections.WriteString(section)
+	}
+
+	title := cfg.Title
+	if title == "" {
+		title = "Slidetty"
+	}
+	colors := cfg.StatusBar.withDefaults()
+	page := strings.NewReplacer(
+		"{{title}}", html.EscapeString(title),
+		"{{author}}", html.EscapeString(cfg.Author),
+		"{{outer}}", html.EscapeString(colors.Outer),
+		"{{inner}}", html.EscapeString(colors.Inner),
+		"{{slides}}", sections.String(),
+	).Replace(htmlTemplate)
+	return os.WriteFile(path, []byte(page), 0o644)
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{title}}</title>
+<style>
+html, body { margin: 0; height: 100%; background: #111827; color: #e5e7eb; font: 20px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+main { height: calc(100% - 2.2em); overflow: auto; }
+.slide { display: none; max-width: 60em; margin: 0 auto; padding: 2em; box-sizing: border-box; }
+.slide.current { display: block; }
+.fragment { visibility: hidden; }
+.fragment.shown { visibility: visible; }
+.dim .fragment { visibility: visible; opacity: .35; }
+.dim .fragment.shown { opacity: 1; }
+.line.dimmed { opacity: .35; }
+h1, h2, h3 { color: #f9fafb; }
+a { color: #93c5fd; }
+code { background: #1f2937; padding: 0 .2em; }
+pre { background: #1f2937; padding: 1em; overflow-x: auto; }
+pre code { padding: 0; }
+blockquote { border-left: 4px solid #4b5563; margin-left: 0; padding-left: 1em; color: #9ca3af; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #4b5563; padding: .2em .6em; }
+.commands { margin-top: 1.5em; }
+.command { background: #162616; padding: .2em .5em; margin: .1em 0; }
+.command button { background: #1a602c; color: #fff; border: 0; margin-right: .6em; cursor: pointer; font: inherit; }
+footer { position: fixed; bottom: 0; left: 0; right: 0; height: 2.2em; line-height: 2.2em; display: flex; background: {{outer}}; color: #fff; }
+footer span { flex: 1; padding: 0 1em; }
+footer .author { background: {{inner}}; text-align: center; }
+footer .title { text-align: right; }
+</style>
+</head>
+<body>
+<main>
+{{slides}}</main>
+<footer><span class="count"></span><span class="author">{{author}}</span><span class="title">{{title}}</span></footer>
+<script>
+(function () {
+  var slides = Array.prototype.slice.call(document.querySelectorAll('.slide'));
+  var count = document.querySelector('footer .count');
+  var current = 0, step = 1;
+
+  function steps(i) { return parseInt(slides[i].getAttribute('data-steps'), 10) || 0; }
+
+  function show() {
+    slides.forEach(function (s, i) { s.classList.toggle('current', i === current); });
+    slides[current].querySelectorAll('.fragment').forEach(function (f) {
+      f.classList.toggle('shown', parseInt(f.getAttribute('data-step'), 10) <= step);
+    });
+    slides[current].querySelectorAll('pre[data-highlight]').forEach(function (pre) {
+      var lines = null;
+      pre.getAttribute('data-highlight').split(';').forEach(function (group) {
+        var parts = group.split('=');
+        if (parseInt(parts[0], 10) <= step) lines = parts[1].split(' ');
+      });
+      pre.querySelectorAll('.line').forEach(function (line, i) {
+        line.classList.toggle('dimmed', lines !== null && lines.indexOf(String(i + 1)) < 0);
+      });
+    });
+    count.textContent = 'Slide ' + (current + 1) + '/' + slides.length;
+    location.hash = 'slide-' + (current + 1);
+  }
+
+  function go(i, atEnd) {
+    if (i < 0 || i >= slides.length) return;
+    current = i;
+    step = atEnd ? Math.max(steps(i), 1) : 1;
+    show();
+  }
+
+  function forward() { if (step < steps(current)) { step++; show(); } else { go(current + 1); } }
+  function back() { if (step > 1) { step--; show(); } else { go(current - 1, true); } }
+
+  document.addEventListener('keydown', function (e) {
+    switch (e.key) {
+      case 'ArrowDown': case 'j': case ' ': case 'PageDown': forward(); break;
+      case 'ArrowUp': case 'k': case 'PageUp': back(); break;
+      case 'ArrowRight': case 'l': go(current + 1); break;
+      case 'ArrowLeft': case 'h': go(current - 1); break;
+      case 'Home': go(0); break;
+      case 'End': go(slides.length - 1); break;
+      default: return;
+    }
+    e.preventDefault();
+  });
+
+  document.addEventListener('click', function (e) {
+    var text = e.target.getAttribute && e.target.getAttribute('data-copy');
+    if (text === null || text === undefined) return;
+    navigator.clipboard.writeText(text).then(function () {
+      e.target.textContent = 'copied';
+      setTimeout(function () { e.target.textContent = 'copy'; }, 1500);
+    });
+  });
+
+  var match = /^#slide-(\d+)$/.exec(location.hash);
+  go(match ? Math.min(parseInt(match[1], 10), slides.length) - 1 : 0);
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+func TestRenderSlideHTML(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "dot.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	png.Encode(f, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	f.Close()
+
+	content := "# !big:braille Hi\n\n```go\nx := 1\n```\n\n:morph:\n\n```go\nx := 2\n```\n\n![dot](dot.png)\n\n:reveal:\n\nLater\n"
+	path := filepath.Join(dir, "01.md")
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100))),
+	)
+	out, err := renderSlideHTML(md, newSlide(content, content, slideMeta{}, path, -1), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"!big", ":morph:", "x := 1", "dot.png"} {
+		if strings.Contains(out, text) {
+			t.Errorf("slide HTML contains %q:\n%s", text, out)
+		}
+	}
+	for _, text := range []string{"⠀", "x := 2", `src="data:image/png;base64,`, `<p class="fragment" data-step="3">Later</p>`} {
+		if !strings.Contains(out, text) {
+			t.Errorf("slide HTML lacks %q:\n%s", text, out)
+		}
+	}
+}
+
+func TestExportHTMLHeadingOnFirstLine(t *testing.T) {
+	content := "# Title\n\n:reveal:\n- a\n- b\n"
+	slides := []slide{newSlide(content, content, slideMeta{}, "01.md", -1)}
+	path := filepath.Join(t.TempDir(), "deck.html")
+	if err := exportHTML(slides, deckConfig{}, path); err != nil {
+		t.Fatal(err)
+	}
+	page, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), `<li class="fragment" data-step="2">b</li>`) {
+		t.Errorf("list item b isn't a fragment of step 2:\n%s", page)
+	}
+}
+
+func TestExportHTMLBundledDecks(t *testing.T) {
+	for _, deck := range []string{"presos/generic", "presos/gitbutler-cli"} {
+		slides, cfg, err := readDeck(deck, "")
+		if err != nil {
+			t.Fatalf("%s: %v", deck, err)
+		}
+		if err := exportHTML(slides, cfg, filepath.Join(t.TempDir(), "deck.html")); err != nil {
+			t.Errorf("%s: %v", deck, err)
+		}
+	}
+}
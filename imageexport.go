@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// glyphShape describes a block, braille or Powerline glyph as filled
+// polygons in unit cell coordinates (0..1, y down), drawn instead of a font
+// glyph so adjacent cells join without gaps and fonts without the glyph
+// still show it.
+type glyphShape struct {
+	polygons [][][2]float64
+	alpha    float64
+}
+
+// brailleDots are the positions of the dots of a braille pattern, in the
+// order of the bits of its code point.
+var brailleDots = [8][2]float64{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3}}
+
+// shapeFor returns the shape to draw for r, if it is one drawn as geometry.
+func shapeFor(r rune) (glyphShape, bool) {
+	box := func(x0, y0, x1, y1 float64) [][2]float64 {
+		return [][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+	}
+	rect := func(x0, y0, x1, y1, alpha float64) glyphShape {
+		return glyphShape{polygons: [][][2]float64{box(x0, y0, x1, y1)}, alpha: alpha}
+	}
+	switch r {
+	case '█':
+		return rect(0, 0, 1, 1, 1), true
+	case '▀':
+		return rect(0, 0, 1, 0.5, 1), true
+	case '▄':
+		return rect(0, 0.5, 1, 1, 1), true
+	case '▌':
+		return rect(0, 0, 0.5, 1, 1), true
+	case '▐':
+		return rect(0.5, 0, 1, 1, 1), true
+	case '░':
+		return rect(0, 0, 1, 1, 0.25), true
+	case '▒':
+		return rect(0, 0, 1, 1, 0.5), true
+	case '▓':
+		return rect(0, 0, 1, 1, 0.75), true
+	case '\uE0B0': // Powerline right-pointing separator
+		return glyphShape{polygons: [][][2]float64{{{0, 0}, {1, 0.5}, {0, 1}}}, alpha: 1}, true
+	case '\uE0B2': // Powerline left-pointing separator
+		return glyphShape{polygons: [][][2]float64{{{1, 0}, {0, 0.5}, {1, 1}}}, alpha: 1}, true
+	}
+	if r >= 0x2800 && r <= 0x28FF {
+		shape := glyphShape{alpha: 1}
+		for bit, dot := range brailleDots {
+			if (r-0x2800)&(1<<bit) != 0 {
+				x, y := 0.1+dot[0]*0.5, 0.05+dot[1]*0.25
+				shape.polygons = append(shape.polygons, box(x, y, x+0.3, y+0.15))
+			}
+		}
+		return shape, true
+	}
+	return glyphShape{}, false
+}
+
+// blend mixes fg over bg with the given opacity.
+func blend(fg, bg color.RGBA, alpha float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a)*alpha + float64(b)*(1-alpha)))
+	}
+	return color.RGBA{mix(fg.R, bg.R), mix(fg.G, bg.G), mix(fg.B, bg.B), 0xFF}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SVG cell metrics, in pixels.
+const (
+	svgFontSize   = 14.0
+	svgCellWidth  = 8.4
+	svgCellHeight = 18.0
+)
+
+// renderSVG draws a screen as a standalone SVG document, text kept as text.
+func renderSVG(s *screen) string {
+	var b strings.Builder
+	width := float64(s.width) * svgCellWidth
+	height := float64(s.height) * svgCellHeight
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(s.page))
+	fmt.Fprintf(&b, `<g font-family="ui-monospace, Menlo, Consolas, monospace" font-size="%g" xml:space="preserve">`+"\n", svgFontSize)
+
+	for y := 0; y < s.height; y++ {
+		top := float64(y) * svgCellHeight
+		s.runs(y, func(x, n int, text []rune, offsets []int, style cellStyle) {
+			left := float64(x) * svgCellWidth
+			if style.bg != s.page {
+				fmt.Fprintf(&b, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s"/>`+"\n",
+					left, top, float64(n)*svgCellWidth, svgCellHeight, hexColor(style.bg))
+			}
+
+			attrs := fmt.Sprintf(`fill="%s"`, hexColor(style.fg))
+			if style.bold {
+				attrs += ` font-weight="bold"`
+			}
+			if style.italic {
+				attrs += ` font-style="italic"`
+			}
+			if style.underline {
+				attrs += ` text-decoration="underline"`
+			}
+
+			// Text is emitted in stretches between shapes, each positioned
+			// at its own column
+			var run []rune
+			runStart := 0
+			flush := func() {
+				if strings.TrimSpace(string(run)) != "" {
+					fmt.Fprintf(&b, `<text x="%g" y="%g" %s>%s</text>`+"\n",
+						left+float64(runStart)*svgCellWidth, top+svgCellHeight*0.75, attrs, html.EscapeString(string(run)))
+				}
+				run = run[:0]
+			}
+			for i, r := range text {
+				if shape, ok := shapeFor(r); ok {
+					flush()
+					cx := left + float64(offsets[i])*svgCellWidth
+					for _, polygon := range shape.polygons {
+						points := make([]string, len(polygon))
+						for j, p := range polygon {
+							points[j] = fmt.Sprintf("%g,%g", cx+p[0]*svgCellWidth, top+p[1]*svgCellHeight)
+						}
+						fmt.Fprintf(&b, `<polygon points="%s" fill="%s"/>`+"\n",
+							strings.Join(points, " "), hexColor(blend(style.fg, style.bg, shape.alpha)))
+					}
+					continue
+				}
+				if len(run) == 0 {
+					runStart = offsets[i]
+				}
+				run = append(run, r)
+			}
+			flush()
+		})
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
+
+// pngFonts holds the faces and cell metrics used to rasterize screens.
+type pngFonts struct {
+	regular font.Face
+	bold    font.Face
+	cellW   int
+	cellH   int
+	ascent  int
+}
+
+func loadPNGFonts(size float64) (*pngFonts, error) {
+	face := func(ttf []byte) (font.Face, error) {
+		f, err := opentype.Parse(ttf)
+		if err != nil {
+			return nil, err
+		}
+		return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	}
+	regular, err := face(gomono.TTF)
+	if err != nil {
+		return nil, err
+	}
+	bold, err := face(gomonobold.TTF)
+	if err != nil {
+		return nil, err
+	}
+	advance, _ := regular.GlyphAdvance('M')
+	metrics := regular.Metrics()
+	return &pngFonts{
+		regular: regular,
+		bold:    bold,
+		cellW:   advance.Ceil(),
+		cellH:   (metrics.Ascent + metrics.Descent).Ceil() + 2,
+		ascent:  metrics.Ascent.Ceil() + 1,
+	}, nil
+}
+
+// fillShape rasterizes a glyph shape of convex polygons into the cell at
+// (x, y).
+func fillShape(img *image.RGBA, shape glyphShape, x, y, w, h int, c color.RGBA) {
+	for _, polygon := range shape.polygons {
+		fillPolygon(img, polygon, x, y, w, h, c)
+	}
+}
+
+func fillPolygon(img *image.RGBA, polygon [][2]float64, x, y, w, h int, c color.RGBA) {
+	inside := func(px, py float64) bool {
+		n := len(polygon)
+		sign := 0.0
+		for i := 0; i < n; i++ {
+			a, b := polygon[i], polygon[(i+1)%n]
+			cross := (b[0]-a[0])*(py-a[1]) - (b[1]-a[1])*(px-a[0])
+			if cross != 0 {
+				if sign != 0 && (cross > 0) != (sign > 0) {
+					return false
+				}
+				sign = cross
+			}
+		}
+		return true
+	}
+	for py := 0; py < h; py++ {
+		for px := 0; px < w; px++ {
+			if inside((float64(px)+0.5)/float64(w), (float64(py)+0.5)/float64(h)) {
+				img.SetRGBA(x+px, y+py, c)
+			}
+		}
+	}
+}
+
+// renderPNG rasterizes a screen with the bundled Go Mono fonts.
+func renderPNG(s *screen, fonts *pngFonts) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, s.width*fonts.cellW, s.height*fonts.cellH))
+	draw.Draw(img, img.Bounds(), image.NewUniform(s.page), image.Point{}, draw.Src)
+
+	for y, row := range s.cells {
+		top := y * fonts.cellH
+		for x, c := range row {
+			left := x * fonts.cellW
+			cellRect := image.Rect(left, top, left+fonts.cellW, top+fonts.cellH)
+			if c.r != 0 && x+1 < len(row) && row[x+1].r == 0 {
+				cellRect.Max.X += fonts.cellW
+			}
+			if c.style.bg != s.page {
+				draw.Draw(img, cellRect, image.NewUniform(c.style.bg), image.Point{}, draw.Src)
+			}
+			if c.r == 0 || c.r == ' ' {
+				continue
+			}
+			if shape, ok := shapeFor(c.r); ok {
+				fillShape(img, shape, left, top, fonts.cellW, fonts.cellH, blend(c.style.fg, c.style.bg, shape.alpha))
+				continue
+			}
+			face := fonts.regular
+			if c.style.bold {
+				face = fonts.bold
+			}
+			d := font.Drawer{
+				Dst:  img,
+				Src:  image.NewUniform(c.style.fg),
+				Face: face,
+				Dot:  fixed.P(left, top+fonts.ascent),
+			}
+			d.DrawString(string(c.r))
+			if c.style.underline {
+				draw.Draw(img, image.Rect(left, top+fonts.ascent+1, cellRect.Max.X, top+fonts.ascent+2), image.NewUniform(c.style.fg), image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
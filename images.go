@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/color/palette"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/image/draw"
+)
+
+// Ways of drawing an image in the terminal.
+const (
+	imagesKitty     = "kitty"     // Kitty graphics protocol, placed with Unicode placeholders
+	imagesITerm     = "iterm"     // iTerm2 inline images
+	imagesSixel     = "sixel"     // DEC sixel graphics
+	imagesHalfBlock = "halfblock" // colored ▀ characters, two pixels per cell
+	imagesBraille   = "braille"   // braille dots, eight pixels per cell
+	imagesOff       = "off"       // leave images as links
+)
+
+// Assumed size of a character cell in pixels, for sixel output and for
+// keeping images' aspect ratio. Cells are about twice as tall as wide.
+const (
+	cellPixelWidth  = 10
+	cellPixelHeight = 20
+)
+
+// imageLineRe matches a paragraph made of a single image.
+var imageLineRe = regexp.MustCompile(`^!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)$`)
+
+// imageMarker stands in for an image in the markdown handed to glamour; its
+// line of output is then replaced by the image.
+const imageMarker = "SLIDETTYIMAGE"
+
+// imageProtocol returns how to draw images: setting when it names a way,
+// otherwise what the terminal is detected to support.
+func imageProtocol(setting string) string {
+	switch setting {
+	case imagesKitty, imagesITerm, imagesSixel, imagesHalfBlock, imagesBraille, imagesOff:
+		return setting
+	}
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen"):
+		// Multiplexers don't pass graphics through unless configured to
+		return imagesHalfBlock
+	case term == "xterm-kitty" || os.Getenv("KITTY_WINDOW_ID") != "" || program == "ghostty":
+		return imagesKitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return imagesITerm
+	case strings.Contains(term, "sixel") || term == "foot" || term == "mlterm" || program == "mintty":
+		return imagesSixel
+	}
+	return imagesHalfBlock
+}
+
+// slideImage is an image of a slide, found by placeImages.
+type slideImage struct {
+	alt  string
+	path string
+}
+
+// placeImages swaps every image that is a paragraph of its own in markdown
+// for a marker line, and returns the images in order. Images with a URL are
+// left as links.
+func placeImages(markdown, dir string) (string, []slideImage) {
+	if !strings.Contains(markdown, "![") {
+		return markdown, nil
+	}
+	lines := strings.Split(markdown, "\n")
+	var images []slideImage
+	var fence codeFence
+	for i, line := range lines {
+		fence.step(line)
+		match := imageLineRe.FindStringSubmatch(strings.TrimSpace(line))
+		if fence.inside() || match == nil || strings.Contains(match[2], "://") {
+			continue
+		}
+		path := match[2]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		lines[i] = fmt.Sprintf("%s%d", imageMarker, len(images))
+		images = append(images, slideImage{alt: match[1], path: path})
+	}
+	return strings.Join(lines, "\n"), images
+}
+
+// drawnImage is an image drawn for the terminal. lines fill the image's
+// cells. prelude, for the Kitty protocol, transmits the image and must be
+// written before lines are.
+type drawnImage struct {
+	lines   []string
+	prelude string
+}
+
+// cachedImage is the last drawing of an image file, and the modification
+// time, protocol and size it was drawn for.
+type cachedImage struct {
+	key   string
+	image drawnImage
+}
+
+var (
+	imagesMu sync.Mutex
+	drawn    = make(map[string]cachedImage) // by path
+)
+
+// drawImage draws the image at path to fit within width by height cells.
+// Only the latest drawing of each file is cached, so resizing the terminal
+// replaces it rather than adding another. An image that can't be read is
+// drawn as an error message.
+func drawImage(img slideImage, protocol string, width, height int) drawnImage {
+	info, err := os.Stat(img.path)
+	if err != nil {
+		return drawnImage{lines: []string{fmt.Sprintf("[image %s: %v]", img.alt, err)}}
+	}
+	key := fmt.Sprintf("%d\x00%s\x00%d\x00%d", info.ModTime().UnixNano(), protocol, width, height)
+	imagesMu.Lock()
+	defer imagesMu.Unlock()
+	if cached, ok := drawn[img.path]; ok && cached.key == key {
+		return cached.image
+	}
+
+	data, err := os.ReadFile(img.path)
+	var decoded image.Image
+	if err == nil {
+		decoded, _, err = image.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return drawnImage{lines: []string{fmt.Sprintf("[image %s: %v]", img.alt, err)}}
+	}
+
+	cols, rows := fitCells(decoded.Bounds().Dx(), decoded.Bounds().Dy(), width, height)
+	var d drawnImage
+	switch protocol {
+	case imagesKitty:
+		d = kittyImage(img.path, decoded, cols, rows)
+	case imagesITerm:
+		d = graphicImage(iTermImage(data, cols, rows), cols, rows)
+	case imagesSixel:
+		d = graphicImage(sixelImage(decoded, cols, rows), cols, rows)
+	case imagesBraille:
+		d = drawnImage{lines: brailleImage(decoded, cols, rows)}
+	default:
+		d = drawnImage{lines: halfBlockImage(decoded, cols, rows)}
+	}
+	drawn[img.path] = cachedImage{key: key, image: d}
+	return d
+}
+
+// fitCells returns the size in cells of a w by h pixel image scaled to fit
+// within width by height cells.
+func fitCells(w, h, width, height int) (cols, rows int) {
+	if w <= 0 || h <= 0 {
+		return 1, 1
+	}
+	aspect := float64(cellPixelHeight) / float64(cellPixelWidth)
+	cols = width
+	rows = int(float64(h) * float64(width) / float64(w) / aspect)
+	if rows > height {
+		rows = height
+		cols = int(float64(w) * float64(height) * aspect / float64(h))
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// scaleImage resizes img to w by h pixels.
+func scaleImage(img image.Image, w, h int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// sgr returns the escape sequence setting the foreground, or background,
+// to c in the terminal's color profile; nothing on terminals without color.
+func sgr(c color.NRGBA, background bool) string {
+	hex := fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	sequence := lipgloss.ColorProfile().Color(hex).Sequence(background)
+	if sequence == "" {
+		return ""
+	}
+	return "\x1b[" + sequence + "m"
+}
+
+// halfBlockImage draws img with upper half blocks, the cell's foreground
+// coloring its top pixel and the background its bottom one. Transparent
+// pixels show the terminal's background.
+func halfBlockImage(img image.Image, cols, rows int) []string {
+	scaled := scaleImage(img, cols, rows*2)
+	lines := make([]string, rows)
+	for y := 0; y < rows; y++ {
+		var b strings.Builder
+		for x := 0; x < cols; x++ {
+			top, bottom := scaled.NRGBAAt(x, 2*y), scaled.NRGBAAt(x, 2*y+1)
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				b.WriteString("\x1b[0m ")
+			case top.A < 128:
+				b.WriteString("\x1b[0m" + sgr(bottom, false) + "▄")
+			case bottom.A < 128:
+				b.WriteString("\x1b[0m" + sgr(top, false) + "▀")
+			default:
+				b.WriteString(sgr(top, false) + sgr(bottom, true) + "▀")
+			}
+		}
+		b.WriteString("\x1b[0m")
+		lines[y] = b.String()
+	}
+	return lines
+}
+
+// brailleImage draws img in braille dots, a dot for every pixel brighter
+// than the image's average, each cell colored with its dots' average color.
+func brailleImage(img image.Image, cols, rows int) []string {
+	scaled := scaleImage(img, cols*2, rows*4)
+	luma := func(c color.NRGBA) int { return (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000 }
+	total, count := 0, 0
+	for y := 0; y < rows*4; y++ {
+		for x := 0; x < cols*2; x++ {
+			if c := scaled.NRGBAAt(x, y); c.A >= 128 {
+				total += luma(c)
+				count++
+			}
+		}
+	}
+	mean := total / max(count, 1)
+
+	// Braille dot numbers for each pixel of a 2x4 block, in row-major order
+	dots := []int{0, 3, 1, 4, 2, 5, 6, 7}
+	lines := make([]string, rows)
+	for y := 0; y < rows; y++ {
+		var b strings.Builder
+		for x := 0; x < cols; x++ {
+			bits, r, g, bl, lit := 0, 0, 0, 0, 0
+			for i, dot := range dots {
+				c := scaled.NRGBAAt(2*x+i%2, 4*y+i/2)
+				if c.A >= 128 && luma(c) > mean {
+					bits |= 1 << dot
+					r, g, bl, lit = r+int(c.R), g+int(c.G), bl+int(c.B), lit+1
+				}
+			}
+			if lit == 0 {
+				b.WriteString(" ")
+				continue
+			}
+			b.WriteString(sgr(color.NRGBA{uint8(r / lit), uint8(g / lit), uint8(bl / lit), 255}, false))
+			b.WriteRune(rune(0x2800 + bits))
+		}
+		b.WriteString("\x1b[0m")
+		lines[y] = b.String()
+	}
+	return lines
+}
+
+// kittyDiacritics are the combining characters that number the rows of a
+// Kitty Unicode placeholder, in the protocol's order.
+var kittyDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D, 0x033E, 0x033F,
+	0x0346, 0x034A, 0x034B, 0x034C, 0x0350, 0x0351, 0x0352, 0x0357,
+	0x035B, 0x0363, 0x0364, 0x0365, 0x0366, 0x0367, 0x0368, 0x0369,
+	0x036A, 0x036B, 0x036C, 0x036D, 0x036E, 0x036F, 0x0483, 0x0484,
+	0x0485, 0x0486, 0x0487, 0x0592, 0x0593, 0x0594, 0x0595, 0x0597,
+	0x0598, 0x0599, 0x059C, 0x059D, 0x059E, 0x059F, 0x05A0, 0x05A1,
+	0x05A8, 0x05A9, 0x05AB, 0x05AC, 0x05AF, 0x05C4, 0x0610, 0x0611,
+	0x0612, 0x0613, 0x0614, 0x0615, 0x0616, 0x0617, 0x0657, 0x0658,
+}
+
+// kittyImage transmits img to a Kitty-compatible terminal with a virtual
+// placement, and draws it with Unicode placeholder characters. Placeholders
+// are ordinary text, so the image moves and clears with the lines holding
+// it.
+func kittyImage(path string, img image.Image, cols, rows int) drawnImage {
+	rows = min(rows, len(kittyDiacritics))
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	id := h.Sum32()&0xFFFFFF | 1
+
+	var encoded bytes.Buffer
+	png.Encode(&encoded, img)
+	payload := base64.StdEncoding.EncodeToString(encoded.Bytes())
+	var prelude strings.Builder
+	for first := true; payload != "" || first; first = false {
+		chunk := payload[:min(len(payload), 4096)]
+		payload = payload[len(chunk):]
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&prelude, "\x1b_Ga=T,U=1,q=2,f=100,i=%d,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&prelude, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+
+	// The image id is given as the placeholders' foreground color. Only the
+	// first cell of a row needs its row and column; the rest follow on.
+	fg := fmt.Sprintf("\x1b[38;2;%d;%d;%dm", id>>16&0xFF, id>>8&0xFF, id&0xFF)
+	lines := make([]string, rows)
+	for y := range lines {
+		lines[y] = fg + "\U0010EEEE" + string(kittyDiacritics[y]) + string(kittyDiacritics[0]) +
+			strings.Repeat("\U0010EEEE", cols-1) + "\x1b[0m"
+	}
+	return drawnImage{lines: lines, prelude: prelude.String()}
+}
+
+// graphicImage lays out an image drawn with an escape sequence that paints
+// pixels over the cells where the cursor is. The image's own lines are left
+// blank, and a line after them moves the cursor back up, draws the image
+// and returns, so it is drawn once the lines it covers have been written.
+func graphicImage(sequence string, cols, rows int) drawnImage {
+	lines := make([]string, rows+1)
+	lines[rows] = fmt.Sprintf("\x1b7\x1b[%dA%s\x1b8", rows, sequence)
+	return drawnImage{lines: lines}
+}
+
+// iTermImage returns the iTerm2 escape sequence showing the image file data
+// in cols by rows cells.
+func iTermImage(data []byte, cols, rows int) string {
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+}
+
+// sixelImage encodes img as sixel graphics covering cols by rows cells.
+func sixelImage(img image.Image, cols, rows int) string {
+	w, h := cols*cellPixelWidth, rows*cellPixelHeight
+	scaled := scaleImage(img, w, h)
+	paletted := image.NewPaletted(scaled.Bounds(), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), scaled, image.Point{})
+
+	var b strings.Builder
+	// P2=1 leaves pixels without a color transparent
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for i, c := range paletted.Palette {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, r*100/0xFFFF, g*100/0xFFFF, bl*100/0xFFFF)
+	}
+	for band := 0; band < h; band += 6 {
+		// One pass over the band per color it uses
+		used := make(map[uint8]bool)
+		for y := band; y < min(band+6, h); y++ {
+			for x := 0; x < w; x++ {
+				if scaled.NRGBAAt(x, y).A >= 128 {
+					used[paletted.ColorIndexAt(x, y)] = true
+				}
+			}
+		}
+		for index := range used {
+			fmt.Fprintf(&b, "#%d", index)
+			run, last := 0, byte(0)
+			flush := func() {
+				switch {
+				case run > 3:
+					fmt.Fprintf(&b, "!%d%c", run, last)
+				case run > 0:
+					b.WriteString(strings.Repeat(string(last), run))
+				}
+			}
+			for x := 0; x < w; x++ {
+				bits := 0
+				for dy := 0; dy < 6 && band+dy < h; dy++ {
+					if paletted.ColorIndexAt(x, band+dy) == index && scaled.NRGBAAt(x, band+dy).A >= 128 {
+						bits |= 1 << dy
+					}
+				}
+				sixel := byte(63 + bits)
+				if sixel != last || run == 0 {
+					flush()
+					run, last = 0, sixel
+				}
+				run++
+			}
+			flush()
+			b.WriteString("$")
+		}
+		b.WriteString("-")
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// expandImages replaces the marker lines placeImages left in rendered
+// slide lines with the images drawn to fit width columns and the rows that
+// height leaves after the slide's text, shared between the images. The
+// Kitty protocol's transmissions are returned separately, to be written
+// ahead of whatever part of the slide is on screen.
+func expandImages(lines []string, images []slideImage, protocol string, width, height int) ([]string, string) {
+	if len(images) == 0 {
+		return lines, ""
+	}
+	rows := max((height-(len(lines)-len(images)))/len(images), 4)
+	var out []string
+	var prelude strings.Builder
+	for _, line := range lines {
+		plain := ansi.Strip(line)
+		index := -1
+		if trimmed := strings.TrimSpace(plain); strings.HasPrefix(trimmed, imageMarker) {
+			fmt.Sscanf(trimmed[len(imageMarker):], "%d", &index)
+		}
+		if index < 0 || index >= len(images) {
+			out = append(out, line)
+			continue
+		}
+		margin := strings.Repeat(" ", len(plain)-len(strings.TrimLeft(plain, " ")))
+		d := drawImage(images[index], protocol, width-len(margin), rows)
+		prelude.WriteString(d.prelude)
+		for _, imageLine := range d.lines {
+			out = append(out, margin+imageLine)
+		}
+	}
+	return out, prelude.String()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// testPNG writes a w by h red image to a new file and returns its path.
+func testPNG(t *testing.T, w, h int) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	path := filepath.Join(t.TempDir(), "red.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFitCells(t *testing.T) {
+	tests := []struct {
+		w, h, width, height int
+		cols, rows          int
+	}{
+		// Cells are twice as tall as wide
+		{100, 100, 40, 40, 40, 20},
+		{200, 100, 40, 40, 40, 10},
+		// Too tall for the width, so the height decides
+		{100, 400, 40, 20, 10, 20},
+		{100, 100, 40, 10, 20, 10},
+		// Never less than a cell
+		{1000, 1, 10, 10, 10, 1},
+		{1, 1000, 10, 10, 1, 10},
+		{0, 100, 40, 20, 1, 1},
+		{100, 0, 40, 20, 1, 1},
+	}
+	for _, tt := range tests {
+		cols, rows := fitCells(tt.w, tt.h, tt.width, tt.height)
+		if cols != tt.cols || rows != tt.rows {
+			t.Errorf("fitCells(%d, %d, %d, %d) = %d, %d; want %d, %d", tt.w, tt.h, tt.width, tt.height, cols, rows, tt.cols, tt.rows)
+		}
+	}
+}
+
+func TestPlaceImages(t *testing.T) {
+	markdown := strings.Join([]string{
+		"# Pictures",
+		"",
+		"![logo](logo.png)",
+		"",
+		"  ![titled]( <img/a.png> \"title\" )",
+		"",
+		"![remote](https://example.com/x.png)",
+		"",
+		"Inline ![icon](icon.png) in text.",
+		"",
+		"```markdown",
+		"![fenced](fenced.png)",
+		"```",
+		"",
+		"~~~~",
+		"```",
+		"![still fenced](fenced.png)",
+		"~~~~",
+		"",
+		"![/abs](/tmp/abs.png)",
+	}, "\n")
+	got, images := placeImages(markdown, "deck")
+	want := []slideImage{
+		{alt: "logo", path: filepath.Join("deck", "logo.png")},
+		{alt: "titled", path: filepath.Join("deck", "img/a.png")},
+		{alt: "/abs", path: "/tmp/abs.png"},
+	}
+	if !reflect.DeepEqual(images, want) {
+		t.Errorf("images = %+v, want %+v", images, want)
+	}
+	lines := strings.Split(got, "\n")
+	markers := map[int]string{2: imageMarker + "0", 4: imageMarker + "1", 19: imageMarker + "2"}
+	for i, line := range lines {
+		if marker, ok := markers[i]; ok {
+			if line != marker {
+				t.Errorf("line %d = %q, want %q", i+1, line, marker)
+			}
+		} else if original := strings.Split(markdown, "\n")[i]; line != original {
+			t.Errorf("line %d changed from %q to %q", i+1, original, line)
+		}
+	}
+
+	if got, images := placeImages("no images here", "deck"); got != "no images here" || images != nil {
+		t.Errorf("placeImages without images = %q, %v", got, images)
+	}
+}
+
+func TestExpandImages(t *testing.T) {
+	path := testPNG(t, 20, 20)
+	images := []slideImage{{alt: "red", path: path}}
+	marker := lipgloss.NewStyle().Bold(true).Render(imageMarker + "0")
+	lines := []string{"Title", "  " + marker, "  " + imageMarker + "7", "End"}
+
+	// Two text lines and a stray marker leave 7 of 10 rows; a square image
+	// 10 columns wide takes 5 of them
+	out, prelude := expandImages(lines, images, imagesHalfBlock, 12, 10)
+	if prelude != "" {
+		t.Errorf("half blocks have a prelude %q", prelude)
+	}
+	if len(out) != 8 || out[0] != "Title" || out[6] != lines[2] || out[7] != "End" {
+		t.Fatalf("expanded lines = %q", out)
+	}
+	for _, line := range out[1:6] {
+		if !strings.HasPrefix(line, "  ") || ansi.StringWidth(line) != 12 || !strings.Contains(line, "▀") {
+			t.Errorf("image line %q isn't 10 half blocks indented by 2", line)
+		}
+	}
+
+	out, prelude = expandImages(lines[:2], images, imagesKitty, 12, 10)
+	if !strings.HasPrefix(prelude, "\x1b_Ga=T") || len(out) != 6 {
+		t.Errorf("kitty: prelude %q, lines %q", prelude, out)
+	}
+
+	missing := []slideImage{{alt: "gone", path: filepath.Join(t.TempDir(), "gone.png")}}
+	out, _ = expandImages([]string{imageMarker + "0"}, missing, imagesHalfBlock, 40, 10)
+	if len(out) != 1 || !strings.HasPrefix(out[0], "[image gone: ") {
+		t.Errorf("missing image drawn as %q", out)
+	}
+}
+
+func TestDrawImageCachesLatestSize(t *testing.T) {
+	path := testPNG(t, 20, 20)
+	img := slideImage{alt: "red", path: path}
+	for width := 10; width <= 40; width += 5 {
+		drawImage(img, imagesHalfBlock, width, 10)
+	}
+	imagesMu.Lock()
+	cached, ok := drawn[path]
+	imagesMu.Unlock()
+	if !ok || len(cached.image.lines) != 10 {
+		t.Fatalf("cached drawing of %s = %v, %v", path, cached, ok)
+	}
+	if d := drawImage(img, imagesHalfBlock, 40, 10); !reflect.DeepEqual(d, cached.image) {
+		t.Error("drawing at the latest size again didn't use the cache")
+	}
+
+	// A changed file is drawn again: the wider image takes half the rows
+	replaced := testPNG(t, 40, 10)
+	data, err := os.ReadFile(replaced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if d := drawImage(img, imagesHalfBlock, 40, 10); len(d.lines) != 5 {
+		t.Errorf("a changed image drew %d lines", len(d.lines))
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Slide layouts set with the layout front matter key.
+const (
+	layoutTwoColumn  = "two-column"  // body split in two at a ||| line, or halfway
+	layoutTitle      = "title"       // centered on the screen
+	layoutSection    = "section"     // a section divider, centered top to bottom
+	layoutImageLeft  = "image-left"  // first image on the left, the rest beside it
+	layoutImageRight = "image-right" // first image on the right
+)
+
+// columnBreak splits a two-column slide's body in two.
+const columnBreak = "|||"
+
+// layoutColumn is one column of a row of columns. percent is its share of
+// the width, 0 for an even split of what other columns leave.
+type layoutColumn struct {
+	markdown string
+	percent  int
+}
+
+// layoutBlock is a part of a slide: markdown spanning the slide's width, or
+// a row of columns.
+type layoutBlock struct {
+	markdown string
+	columns  []layoutColumn
+}
+
+// isDirective reports whether line is the fenced-div directive name, such
+// as ":::columns", with anything after the name returned as its argument.
+func isDirective(line, name string) (arg string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, ":::") {
+		return "", false
+	}
+	fields := strings.Fields(strings.TrimPrefix(trimmed, ":::"))
+	if name == "" {
+		return "", len(fields) == 0
+	}
+	if len(fields) == 0 || fields[0] != name {
+		return "", false
+	}
+	return strings.Join(fields[1:], " "), true
+}
+
+// parseColumns splits markdown into blocks at ":::columns" fences. Inside
+// one, each ":::column" line starts a column, optionally followed by its
+// width such as "60%", and a bare ":::" ends the row.
+func parseColumns(markdown string) []layoutBlock {
+	var blocks []layoutBlock
+	var text []string
+	var row *layoutBlock
+	var column []string
+	var fence codeFence
+
+	flushColumn := func() {
+		if row != nil && column != nil {
+			row.columns[len(row.columns)-1].markdown = strings.Join(column, "\n")
+		}
+		column = nil
+	}
+	for _, line := range strings.Split(markdown, "\n") {
+		fence.step(line)
+		if !fence.inside() {
+			if _, ok := isDirective(line, "columns"); ok && row == nil {
+				blocks = append(blocks, layoutBlock{markdown: strings.Join(text, "\n")})
+				text = nil
+				row = &layoutBlock{}
+				continue
+			}
+			if arg, ok := isDirective(line, "column"); ok && row != nil {
+				flushColumn()
+				percent, _ := strconv.Atoi(strings.TrimSuffix(arg, "%"))
+				row.columns = append(row.columns, layoutColumn{percent: percent})
+				column = []string{}
+				continue
+			}
+			if _, ok := isDirective(line, ""); ok && row != nil {
+				flushColumn()
+				blocks = append(blocks, *row)
+				row = nil
+				continue
+			}
+		}
+		if row != nil {
+			// Text between ":::columns" and the first ":::column" is dropped
+			if column != nil {
+				column = append(column, line)
+			}
+			continue
+		}
+		text = append(text, line)
+	}
+	if row != nil {
+		// An unclosed row runs to the end of the slide
+		flushColumn()
+		blocks = append(blocks, *row)
+	}
+	if len(text) > 0 {
+		blocks = append(blocks, layoutBlock{markdown: strings.Join(text, "\n")})
+	}
+	return blocks
+}
+
+// splitTitle separates a leading heading from the rest of markdown.
+func splitTitle(markdown string) (title, body string) {
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			return strings.Join(lines[:i+1], "\n"), strings.Join(lines[i+1:], "\n")
+		}
+		break
+	}
+	return "", markdown
+}
+
+// splitHalfway splits markdown at the blank line outside code fences that
+// is nearest its middle, for two-column slides without a ||| line.
+func splitHalfway(markdown string) (left, right string) {
+	lines := strings.Split(markdown, "\n")
+	best := -1
+	var fence codeFence
+	for i, line := range lines {
+		fence.step(line)
+		if !fence.inside() && strings.TrimSpace(line) == "" && i > 0 {
+			if best < 0 || abs(i-len(lines)/2) < abs(best-len(lines)/2) {
+				best = i
+			}
+		}
+	}
+	if best < 0 {
+		return markdown, ""
+	}
+	return strings.Join(lines[:best], "\n"), strings.Join(lines[best+1:], "\n")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// layoutBlocks splits a slide's markdown into the blocks layout and its
+// ":::columns" fences call for. Layouts that only position the slide, and
+// unknown ones, leave it as one block. A slide with ":::columns" fences is
+// laid out by them, even if its layout makes columns too.
+func layoutBlocks(markdown, layout string) []layoutBlock {
+	blocks := parseColumns(markdown)
+	for _, block := range blocks {
+		if block.columns != nil {
+			return blocks
+		}
+	}
+	switch layout {
+	case layoutTwoColumn:
+		title, body := splitTitle(markdown)
+		var left, right []string
+		columns := &left
+		var fence codeFence
+		for _, line := range strings.Split(body, "\n") {
+			fence.step(line)
+			if !fence.inside() && strings.TrimSpace(line) == columnBreak && columns == &left {
+				columns = &right
+				continue
+			}
+			*columns = append(*columns, line)
+		}
+		l, r := strings.Join(left, "\n"), strings.Join(right, "\n")
+		if columns == &left {
+			l, r = splitHalfway(body)
+		}
+		return []layoutBlock{{markdown: title}, {columns: []layoutColumn{{markdown: l}, {markdown: r}}}}
+
+	case layoutImageLeft, layoutImageRight:
+		title, body := splitTitle(markdown)
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			if !imageLineRe.MatchString(strings.TrimSpace(line)) {
+				continue
+			}
+			image := layoutColumn{markdown: line, percent: 40}
+			rest := layoutColumn{markdown: strings.Join(append(append([]string{}, lines[:i]...), lines[i+1:]...), "\n")}
+			columns := []layoutColumn{image, rest}
+			if layout == layoutImageRight {
+				columns = []layoutColumn{rest, image}
+			}
+			return []layoutBlock{{markdown: title}, {columns: columns}}
+		}
+	}
+	return blocks
+}
+
+// shareWidth shares width between columns: each gets its percentage, and
+// those without one split what is left evenly.
+func shareWidth(columns []layoutColumn, width int) []int {
+	widths := make([]int, len(columns))
+	left, unsized := width, 0
+	for i, c := range columns {
+		if c.percent > 0 && c.percent < 100 {
+			widths[i] = width * c.percent / 100
+			left -= widths[i]
+		} else {
+			unsized++
+		}
+	}
+	for i, c := range columns {
+		if widths[i] == 0 && (c.percent <= 0 || c.percent >= 100) {
+			widths[i] = max(left/max(unsized, 1), 1)
+		}
+	}
+	return widths
+}
+
+// renderLayout renders markdown laid out as layout and its ":::columns"
+// fences say, width columns wide. render draws one block or column's
+// markdown at a width and returns its lines.
+func renderLayout(markdown, layout string, width int, render func(markdown string, width int) []string) []string {
+	blocks := layoutBlocks(markdown, layout)
+	if len(blocks) == 1 && blocks[0].columns == nil {
+		return render(markdown, width)
+	}
+	var lines []string
+	for _, block := range blocks {
+		if block.columns == nil {
+			if strings.TrimSpace(block.markdown) != "" {
+				lines = append(lines, trimBlankLines(render(block.markdown, width))...)
+				lines = append(lines, "")
+			}
+			continue
+		}
+		widths := shareWidth(block.columns, width)
+		rendered := make([]string, len(block.columns))
+		for i, column := range block.columns {
+			style := lipgloss.NewStyle().Width(widths[i]).MaxWidth(widths[i])
+			rendered[i] = style.Render(strings.Join(trimBlankLines(render(column.markdown, widths[i])), "\n"))
+		}
+		lines = append(lines, strings.Split(lipgloss.JoinHorizontal(lipgloss.Top, rendered...), "\n")...)
+		lines = append(lines, "")
+	}
+	// Blank lines frame the slide the way glamour frames a document
+	return append([]string{""}, lines...)
+}
+
+// hasColumns reports whether markdown laid out as layout has columns.
+func hasColumns(markdown, layout string) bool {
+	for _, block := range layoutBlocks(markdown, layout) {
+		if block.columns != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// layoutRenderers render the columns of slides shown outside the
+// presentation itself: in the editor preview, presenter console and
+// overview.
+var layoutRenderers = newFitCache()
+
+// renderSlideLayout renders a slide's markdown, laid out as layout, with r,
+// which wraps at width. Columns are rendered at their own widths.
+func renderSlideLayout(r *glamour.TermRenderer, theme, markdown, layout string, width int) (string, error) {
+	if !hasColumns(markdown, layout) {
+		rendered, err := r.Render(markdown)
+		return dimHidden(rendered), err
+	}
+	var renderErr error
+	lines := renderLayout(markdown, layout, width, func(markdown string, width int) []string {
+		rendered, err := layoutRenderers.renderer(theme, width).Render(markdown)
+		if err != nil {
+			renderErr = err
+		}
+		return strings.Split(strings.TrimRight(dimHidden(rendered), "\n"), "\n")
+	})
+	return strings.Join(lines, "\n"), renderErr
+}
+
+// layoutCentering returns how a slide's layout centers it, on top of the
+// deck's center setting.
+func layoutCentering(layout string) (horizontal, vertical bool) {
+	switch layout {
+	case layoutTitle:
+		return true, true
+	case layoutSection:
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLayoutBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		layout   string
+		want     []layoutBlock
+	}{
+		{
+			"two-column at break",
+			"# Title\nleft\n|||\nright",
+			layoutTwoColumn,
+			[]layoutBlock{{markdown: "# Title"}, {columns: []layoutColumn{{markdown: "left"}, {markdown: "right"}}}},
+		},
+		{
+			"break in fence",
+			"# Title\n```\n|||\n```\n|||\nright",
+			layoutTwoColumn,
+			[]layoutBlock{{markdown: "# Title"}, {columns: []layoutColumn{{markdown: "```\n|||\n```"}, {markdown: "right"}}}},
+		},
+		{
+			"columns fences win over two-column",
+			"# Title\n:::columns\n:::column 60%\nleft\n:::column\nright\n:::",
+			layoutTwoColumn,
+			[]layoutBlock{{markdown: "# Title"}, {columns: []layoutColumn{{markdown: "left", percent: 60}, {markdown: "right"}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := layoutBlocks(tt.markdown, tt.layout); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("layoutBlocks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
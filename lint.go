@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// Size slides are checked at when neither the flags nor the deck's target
+// give one, matching `slidetty render`.
+const (
+	defaultLintWidth  = 100
+	defaultLintHeight = 30
+)
+
+// runLint implements `slidetty lint [flags] [deck]`: it reports every slide
+// whose fully revealed content is taller than the space the presentation
+// has for it at the target size, and fails if there are any.
+func runLint(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: slidetty lint [flags] [deck]")
+		fs.PrintDefaults()
+	}
+	width := fs.Int("width", 0, "terminal width in columns (default: the deck's target width, else 100)")
+	height := fs.Int("height", 0, "terminal height in rows (default: the deck's target height, else 30)")
+	fs.Parse(args)
+
+	root, deckFile, err := resolveDeck(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	slides, cfg, err := readDeck(root, deckFile)
+	if err != nil {
+		return err
+	}
+	if len(slides) == 0 {
+		return fmt.Errorf("no slides found in %s", root)
+	}
+	if *width <= 0 {
+		*width = orDefault(cfg.Target.Width, defaultLintWidth)
+	}
+	if *height <= 0 {
+		*height = orDefault(cfg.Target.Height, defaultLintHeight)
+	}
+
+	m := newStaticModel(root, deckFile, slides, cfg, *width, *height)
+	overflowing := 0
+	for i, s := range m.slides {
+		m.revealProgress[i] = s.reveal.totalItems()
+		lines := len(m.slideLines(i))
+		fit := m.contentHeight(i)
+		if lines <= fit {
+			continue
+		}
+		overflowing++
+		name := filepath.Base(s.path)
+		if s.section >= 0 {
+			name = fmt.Sprintf("%s (section %d)", name, s.section+1)
+		}
+		fmt.Fprintf(out, "slide %d, %s: %d lines of content, but only %d fit at %dx%d\n",
+			i+1, name, lines, fit, *width, *height)
+	}
+	if overflowing > 0 {
+		return fmt.Errorf("%d of %d slides don't fit at %dx%d", overflowing, len(m.slides), *width, *height)
+	}
+	return nil
+}
+
+// orDefault returns value, or fallback when value is unset.
+func orDefault(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
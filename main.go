@@ -3,48 +3,72 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 type model struct {
-	slides         []string
-	slidePaths     []string
-	currentSlide   int
-	renderer       *glamour.TermRenderer
-	progress       progress.Model
-	width          int
-	height         int
-	title          string
-	author         string
-	err            error
-	revealConfigs  []revealConfig
-	revealProgress map[int]int
-	showEditor     bool
-	editor         textarea.Model
-	editorPath     string
-	commandBlocks  [][]string // commands for each slide
-	notification   string
+	root              string // deck directory every slide and metadata file is resolved from
+	deckFile          string // single markdown file holding every slide, if any
+	config            deckConfig
+	slides            []slide
+	currentSlide      int
+	renderer          *glamour.TermRenderer
+	progress          progress.Model
+	width             int
+	height            int
+	err               error
+	watcher           *deckWatcher
+	sync              *syncServer // presenter console connection, nil if unavailable
+	static            bool        // rendering frames for export; progress bars skip their animation
+	revealProgress    map[int]int
+	scrollOffsets     map[int]int // lines each slide is scrolled down by
+	fit               *fitCache
+	images            string           // how images are drawn, one of the images* constants
+	morph             *morphAnimation  // code morph being animated, nil when none is
+	transition        *slideTransition // transition to the current slide, nil when none is playing
+	transitions       int              // transitions started, for their ids
+	morphs            int              // code morphs started, for their ids
+	slowLink          bool             // frames arrived late, so animations are off
+	mouse             bool             // the mouse is captured, as the deck's mouse setting asks
+	clipboardSequence string           // OSC 52 sequence for the next frame to write
+	showEditor        bool
+	editor            slideEditor
+	notification      string
 	notificationTimer int
+	output            *outputPanel // last command run from a slide, nil until one runs
+	pendingCommand    string       // command waiting for confirmation before it runs
+	commandRuns       int
+	confirmDelete     bool   // waiting for 'y' before deleting the current slide
+	focusPath         string // slide file to show once the deck reloads
+	showOverview      bool
+	overviewCursor    int      // slide selected in the overview
+	thumbnails        []string // rendered slides shown in the overview
+	prompting         bool
+	prompt            textinput.Model // ":" go-to-slide or "/" search prompt
+	searchQuery       string          // highlighted in slides until cleared with esc
+	searchResults     []int           // slides matching searchQuery, in deck order
 	// Timer fields
-	timerDuration    time.Duration // Total presentation duration
-	timerStartTime   time.Time     // When timer was started
-	timerElapsed     time.Duration // Elapsed time when paused
-	timerRunning     bool          // Whether timer is currently running
-	timerProgress    progress.Model // Progress bar for timer
-	waitingForReset  bool          // Whether waiting for 'y' confirmation after 'p' press
-	blinkCounter     int           // Counter for blinking paused text
-	timerTicking     bool          // Whether timer tick loop is active
+	timerDuration   time.Duration  // Total presentation duration
+	timerStartTime  time.Time      // When timer was started
+	timerElapsed    time.Duration  // Elapsed time when paused
+	timerRunning    bool           // Whether timer is currently running
+	timerProgress   progress.Model // Progress bar for timer
+	waitingForReset bool           // Whether waiting for 'y' confirmation after 'p' press
+	blinkCounter    int            // Counter for blinking paused text
+	timerTicking    bool           // Whether timer tick loop is active
 }
 
 type errMsg error
@@ -53,17 +77,11 @@ type tickMsg struct{}
 
 type timerTickMsg struct{}
 
-type slideReloadedMsg struct {
-	slideIndex    int
-	content       string
-	config        revealConfig
-	path          string
-	commandBlock  []string
-}
-
 type revealConfig struct {
 	directiveLines []int
-	items          [][]int
+	items          [][]int // lines each reveal step shows, in step order
+	highlights     []codeHighlight
+	morphs         []codeMorph
 }
 
 type commandBlock struct {
@@ -74,35 +92,40 @@ func (rc revealConfig) totalItems() int {
 	return len(rc.items)
 }
 
-func loadTheme() string {
-	// Try current directory first, then slides directory
-	paths := []string{"_theme.md", "slides/_theme.md"}
-	for _, path := range paths {
-		if themeContent, err := os.ReadFile(path); err == nil {
-			theme := strings.TrimSpace(string(themeContent))
-			if theme != "" {
-				return theme
-			}
-		}
+// wrapWidth returns the word-wrap width for the current terminal, capped by
+// the deck's configured width.
+func (m model) wrapWidth() int {
+	width := 80
+	if m.width > 0 {
+		width = m.width - 4
+	}
+	if m.config.WordWrap > 0 && m.config.WordWrap < width {
+		width = m.config.WordWrap
 	}
-	return "auto" // fallback to auto if no theme file or empty
+	return width
 }
 
-func initialModel() model {
-	// Initialize glamour renderer with theme from _theme.md
-	theme := loadTheme()
+// newRenderer builds a glamour renderer for the given theme ("auto" or a
+// style path) wrapping at width columns.
+func newRenderer(theme string, width int) *glamour.TermRenderer {
 	var r *glamour.TermRenderer
 	if theme == "auto" {
 		r, _ = glamour.NewTermRenderer(
 			glamour.WithAutoStyle(),
-			glamour.WithWordWrap(80),
+			glamour.WithWordWrap(width),
 		)
 	} else {
 		r, _ = glamour.NewTermRenderer(
 			glamour.WithStylePath(theme),
-			glamour.WithWordWrap(80),
+			glamour.WithWordWrap(width),
 		)
 	}
+	return r
+}
+
+func initialModel(root, deckFile string, watcher *deckWatcher, sync *syncServer) model {
+	// Initialize glamour renderer; the deck's theme is applied once it loads
+	r := newRenderer("auto", 80)
 
 	// Initialize progress bar with gradient
 	prog := progress.New(progress.WithDefaultGradient())
@@ -111,191 +134,90 @@ func initialModel() model {
 	timerProg := progress.New(progress.WithSolidFill("#FF6B35"))
 
 	return model{
-		slides:         []string{},
-		slidePaths:     []string{},
+		root:           root,
+		deckFile:       deckFile,
+		watcher:        watcher,
+		sync:           sync,
+		slides:         []slide{},
 		currentSlide:   0,
 		renderer:       r,
 		progress:       prog,
-		title:          "",
-		author:         "",
-		revealConfigs:  nil,
 		revealProgress: make(map[int]int),
-		commandBlocks:  [][]string{},
+		scrollOffsets:  make(map[int]int),
+		fit:            newFitCache(),
 		timerProgress:  timerProg,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return loadSlides
+	return tea.Batch(loadSlides(m.root, m.deckFile), m.watcher.wait(), m.sync.wait())
 }
 
-func loadSlides() tea.Msg {
-	files, err := os.ReadDir(".")
-	if err != nil {
-		return errMsg(err)
-	}
-
-	var slides []string
-	var filenames []string
-	var title string
-	var author string
-	var configs []revealConfig
-	var paths []string
-	var commandBlocks [][]string
-	var timerDuration time.Duration
-
-	// Load title from _title.md if it exists (check current dir first, then slides dir)
-	titlePaths := []string{"_title.md", "slides/_title.md"}
-	for _, path := range titlePaths {
-		if titleContent, err := os.ReadFile(path); err == nil {
-			title = strings.TrimSpace(string(titleContent))
-			break
-		}
-	}
-
-	// Load author from _author.md if it exists (check current dir first, then slides dir)
-	authorPaths := []string{"_author.md", "slides/_author.md"}
-	for _, path := range authorPaths {
-		if authorContent, err := os.ReadFile(path); err == nil {
-			author = strings.TrimSpace(string(authorContent))
-			break
-		}
-	}
-
-	// Load timer duration from _time if it exists (check current dir first, then slides dir)
-	timePaths := []string{"_time", "slides/_time"}
-	for _, path := range timePaths {
-		if timeContent, err := os.ReadFile(path); err == nil {
-			timeStr := strings.TrimSpace(string(timeContent))
-			if minutes, parseErr := time.ParseDuration(timeStr + "m"); parseErr == nil {
-				timerDuration = minutes
-				break
-			}
-		}
-	}
-
-	// Collect markdown files (excluding files starting with underscore)
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".md" && !strings.HasPrefix(file.Name(), "_") {
-			filenames = append(filenames, file.Name())
-		}
-	}
-
-	// Sort filenames to ensure consistent order
-	sort.Strings(filenames)
-
-	// Read file contents
-	for _, filename := range filenames {
-		content, err := os.ReadFile(filename)
-		if err != nil {
-			return errMsg(err)
-		}
-		slide := string(content)
-		slides = append(slides, slide)
-		configs = append(configs, analyzeReveal(slide))
-		paths = append(paths, filename)
-		commandBlocks = append(commandBlocks, parseCommandBlocks(slide))
-	}
-
-	return slidesLoadedMsg{slides: slides, title: title, author: author, revealConfigs: configs, paths: paths, commandBlocks: commandBlocks, timerDuration: timerDuration}
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// revealProgress is shared with the updated model, so keep the step
+	step := m.revealProgress[m.currentSlide]
+	next, cmd := m.update(msg)
+	updated := next.(model)
+	if transition := startTransition(&updated, m); transition != nil {
+		cmd = tea.Batch(cmd, transition)
+	}
+	if morph := startMorph(&updated, m.currentSlide, step); morph != nil {
+		cmd = tea.Batch(cmd, morph)
+	}
+	updated.sync.publish(updated.syncState())
+	return updated, cmd
 }
 
-func reloadSlide(slideIndex int) tea.Cmd {
-	return func() tea.Msg {
-		files, err := os.ReadDir(".")
-		if err != nil {
-			return errMsg(err)
-		}
-
-		var filenames []string
-
-		// Collect markdown files (excluding files starting with underscore)
-		for _, file := range files {
-			if filepath.Ext(file.Name()) == ".md" && !strings.HasPrefix(file.Name(), "_") {
-				filenames = append(filenames, file.Name())
-			}
-		}
-
-		// Sort filenames to ensure consistent order
-		sort.Strings(filenames)
-
-		// Check if slideIndex is valid
-		if slideIndex < 0 || slideIndex >= len(filenames) {
-			return errMsg(fmt.Errorf("invalid slide index: %d", slideIndex))
-		}
-
-		// Read the specific slide content
-		content, err := os.ReadFile(filenames[slideIndex])
-		if err != nil {
-			return errMsg(err)
-		}
-
-		slide := string(content)
-		return slideReloadedMsg{slideIndex: slideIndex, content: slide, config: analyzeReveal(slide), path: filenames[slideIndex], commandBlock: parseCommandBlocks(slide)}
+// syncState snapshots what a connected presenter console mirrors.
+func (m model) syncState() syncState {
+	return syncState{
+		Slide:         m.currentSlide,
+		Step:          m.revealProgress[m.currentSlide],
+		TimerDuration: m.timerDuration,
+		TimerElapsed:  m.timerElapsed,
+		TimerStarted:  m.timerStartTime,
+		TimerRunning:  m.timerRunning,
 	}
 }
 
-type slidesLoadedMsg struct {
-	slides        []string
-	paths         []string
-	title         string
-	author        string
-	revealConfigs []revealConfig
-	commandBlocks [][]string
-	timerDuration time.Duration
-}
-
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.showEditor {
 		switch msg := msg.(type) {
 		case tea.WindowSizeMsg:
 			m.width = msg.Width
 			m.height = msg.Height
 			if m.renderer != nil {
-				theme := loadTheme()
-				var r *glamour.TermRenderer
-				if theme == "auto" {
-					r, _ = glamour.NewTermRenderer(
-						glamour.WithAutoStyle(),
-						glamour.WithWordWrap(msg.Width-4),
-					)
-				} else {
-					r, _ = glamour.NewTermRenderer(
-						glamour.WithStylePath(theme),
-						glamour.WithWordWrap(msg.Width-4),
-					)
-				}
-				m.renderer = r
+				m.renderer = newRenderer(m.config.themeName(), m.wrapWidth())
 			}
 			m.progress.Width = msg.Width - 4
-			m.editor.SetWidth(msg.Width)
-			m.editor.SetHeight(msg.Height - 3)
+			m.editor.resize(m.config.themeName(), msg.Width, msg.Height)
 			return m, nil
 
 		case tea.KeyMsg:
 			switch msg.Type {
 			case tea.KeyEsc:
-				m.editor.Blur()
+				if m.editor.searching {
+					break
+				}
+				// Closing with unsaved changes needs a second Esc
+				if m.editor.dirty() && !m.editor.confirmDiscard {
+					m.editor.confirmDiscard = true
+					return m, nil
+				}
 				m.showEditor = false
-				m.editorPath = ""
 				return m, nil
 			case tea.KeyCtrlS:
-				content := m.editor.Value()
-				if m.editorPath != "" {
-					if err := os.WriteFile(m.editorPath, []byte(content), 0o644); err != nil {
-						m.err = err
+				content := m.editor.textarea.Value()
+				if path := m.editor.base.path; path != "" {
+					if err := writeSection(path, m.editor.base.section, content); err != nil {
+						// The editor stays open with the edit, so it can be fixed
+						m.editor.message = fmt.Sprintf("not saved: %v", err)
 						return m, nil
 					}
 				}
 				if m.currentSlide >= 0 && m.currentSlide < len(m.slides) {
-					m.slides[m.currentSlide] = content
-					if len(m.revealConfigs) != len(m.slides) {
-						newConfigs := make([]revealConfig, len(m.slides))
-						copy(newConfigs, m.revealConfigs)
-						m.revealConfigs = newConfigs
-					}
-					cfg := analyzeReveal(content)
-					m.revealConfigs[m.currentSlide] = cfg
+					m.slides[m.currentSlide] = m.slides[m.currentSlide].withRaw(content)
+					cfg := m.slides[m.currentSlide].reveal
 					if cfg.totalItems() > 0 {
 						if m.revealProgress == nil {
 							m.revealProgress = make(map[int]int)
@@ -305,24 +227,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						delete(m.revealProgress, m.currentSlide)
 					}
 				}
-				if m.currentSlide >= 0 && m.currentSlide < len(m.slidePaths) && m.editorPath != "" {
-					m.slidePaths[m.currentSlide] = m.editorPath
-				}
-				m.editor.Blur()
 				m.showEditor = false
-				m.editorPath = ""
 				m.err = nil
-				return m, reloadSlide(m.currentSlide)
+				return m, reloadSlide(m.currentSlide, m.currentPath(), m.currentSection())
 			}
 			var cmd tea.Cmd
-			m.editor, cmd = m.editor.Update(msg)
+			m.editor, cmd = m.editor.update(msg)
 			return m, cmd
 		case errMsg:
 			m.err = msg
 			return m, nil
+		case remoteKeyMsg:
+			// The presenter console can't drive slides behind the editor
+			return m, m.sync.wait()
+		case deckChangedMsg, slidesLoadedMsg, commandOutputMsg, commandExitedMsg:
+			// Handled below so the deck stays live while editing
 		default:
 			var cmd tea.Cmd
-			m.editor, cmd = m.editor.Update(msg)
+			m.editor, cmd = m.editor.update(msg)
 			return m, cmd
 		}
 	}
@@ -332,45 +254,120 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		if m.renderer != nil {
-			theme := loadTheme()
-			var r *glamour.TermRenderer
-			if theme == "auto" {
-				r, _ = glamour.NewTermRenderer(
-					glamour.WithAutoStyle(),
-					glamour.WithWordWrap(msg.Width-4),
-				)
-			} else {
-				r, _ = glamour.NewTermRenderer(
-					glamour.WithStylePath(theme),
-					glamour.WithWordWrap(msg.Width-4),
-				)
-			}
-			m.renderer = r
+			m.renderer = newRenderer(m.config.themeName(), m.wrapWidth())
 		}
 		m.progress.Width = msg.Width - 4
 		m.timerProgress.Width = msg.Width - 4
+		if m.output != nil {
+			m.output.run.resize(m.outputPanelSize())
+		}
+		if m.showOverview {
+			m.thumbnails = m.renderThumbnails()
+		}
 		return m, nil
 
+	case commandOutputMsg:
+		if m.output == nil || m.output.run == nil || msg.id != m.output.run.id {
+			return m, nil
+		}
+		m.output.write(msg.data)
+		if m.output.scroll > 0 {
+			// Keep the scrolled-back view still while output arrives
+			_, rows := m.outputPanelSize()
+			m.output.scrollBy(strings.Count(string(msg.data), "\n"), rows)
+		}
+		return m, m.output.run.wait()
+
+	case commandExitedMsg:
+		if m.output == nil || m.output.run == nil || msg.id != m.output.run.id {
+			return m, nil
+		}
+		m.output.exited(msg.err)
+		return m, nil
+
+	case remoteKeyMsg:
+		next, cmd := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(msg.key)})
+		return next, tea.Batch(cmd, m.sync.wait())
+
+	case deckChangedMsg:
+		return m, tea.Batch(loadSlides(m.root, m.deckFile), m.watcher.wait())
+
 	case slidesLoadedMsg:
+		// A deck that loads again clears the error of a reload that failed,
+		// such as one of a half-saved deck.yaml
+		m.err = nil
+		// A reload after a change on disk keeps the presenter on the same
+		// slide by file rather than by index, along with its reveal progress.
+		currentKey := ""
+		if m.currentSlide < len(m.slides) {
+			currentKey = m.slides[m.currentSlide].key()
+		}
+		if m.focusPath != "" {
+			currentKey = slide{path: m.focusPath, section: -1}.key()
+			m.focusPath = ""
+		}
+		progressByKey := make(map[string]int, len(m.revealProgress))
+		for idx, shown := range m.revealProgress {
+			if idx < len(m.slides) {
+				progressByKey[m.slides[idx].key()] = shown
+			}
+		}
+		scrollByKey := make(map[string]int, len(m.scrollOffsets))
+		for idx, offset := range m.scrollOffsets {
+			if idx < len(m.slides) {
+				scrollByKey[m.slides[idx].key()] = offset
+			}
+		}
+
 		m.slides = msg.slides
-		m.slidePaths = msg.paths
-		m.title = msg.title
-		m.author = msg.author
-		m.revealConfigs = msg.revealConfigs
-		m.commandBlocks = msg.commandBlocks
-		m.timerDuration = msg.timerDuration
-		m.revealProgress = make(map[int]int, len(msg.revealConfigs))
-		for idx, cfg := range msg.revealConfigs {
-			if cfg.totalItems() > 0 {
-				m.revealProgress[idx] = 1
+		m.config = msg.config
+		m.timerDuration = msg.config.duration()
+		m.images = imageProtocol(m.config.Images)
+		if m.static && m.images != imagesBraille && m.images != imagesOff {
+			// Frames are saved rather than shown, so draw images as text
+			m.images = imagesHalfBlock
+		}
+		m.renderer = newRenderer(m.config.themeName(), m.wrapWidth())
+		// Capturing the mouse takes text selection away from the terminal,
+		// so it is only done for decks that ask for wheel scrolling
+		var mouseCmd tea.Cmd
+		if m.config.capturesMouse() != m.mouse && !m.static {
+			m.mouse = m.config.capturesMouse()
+			mouseCmd = tea.DisableMouse
+			if m.mouse {
+				mouseCmd = tea.EnableMouseCellMotion
+			}
+		}
+		m.revealProgress = make(map[int]int, len(msg.slides))
+		m.scrollOffsets = make(map[int]int, len(scrollByKey))
+		for idx, s := range msg.slides {
+			if s.key() == currentKey {
+				m.currentSlide = idx
+			}
+			if offset, ok := scrollByKey[s.key()]; ok {
+				m.scrollOffsets[idx] = offset
+			}
+			if total := s.reveal.totalItems(); total > 0 {
+				shown, ok := progressByKey[s.key()]
+				if !ok {
+					shown = 1
+				}
+				m.revealProgress[idx] = clampRevealProgress(shown, total)
 			}
 		}
 		if len(m.slides) == 0 {
-			return m, nil
+			return m, mouseCmd
 		}
 		if m.currentSlide >= len(m.slides) {
 			m.currentSlide = len(m.slides) - 1
 		}
+		if m.searchQuery != "" {
+			m.searchResults = searchSlides(m.slides, m.searchQuery)
+		}
+		if m.showOverview {
+			m.overviewCursor = clampInt(m.overviewCursor, 0, len(m.slides)-1)
+			m.thumbnails = m.renderThumbnails()
+		}
 		percentage := float64(m.currentSlide+1) / float64(len(m.slides))
 		cmd := m.progress.SetPercent(percentage)
 
@@ -381,33 +378,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			timerCmd = doTimerTick()
 		}
 
-		return m, tea.Batch(cmd, timerCmd)
+		return m, tea.Batch(cmd, timerCmd, mouseCmd)
 
 	case slideReloadedMsg:
 		if msg.slideIndex >= 0 && msg.slideIndex < len(m.slides) {
-			m.slides[msg.slideIndex] = msg.content
-			if len(m.revealConfigs) != len(m.slides) {
-				newConfigs := make([]revealConfig, len(m.slides))
-				copy(newConfigs, m.revealConfigs)
-				m.revealConfigs = newConfigs
-			}
-			if len(m.slidePaths) != len(m.slides) {
-				newPaths := make([]string, len(m.slides))
-				copy(newPaths, m.slidePaths)
-				m.slidePaths = newPaths
-			}
-			if len(m.commandBlocks) != len(m.slides) {
-				newCommandBlocks := make([][]string, len(m.slides))
-				copy(newCommandBlocks, m.commandBlocks)
-				m.commandBlocks = newCommandBlocks
-			}
-			m.revealConfigs[msg.slideIndex] = msg.config
-			m.commandBlocks[msg.slideIndex] = msg.commandBlock
-			if msg.path != "" {
-				m.slidePaths[msg.slideIndex] = msg.path
-			}
+			msg.slide.sectionName = m.slides[msg.slideIndex].sectionName
+			msg.slide.deckReveal = m.slides[msg.slideIndex].deckReveal
+			m.slides[msg.slideIndex] = msg.slide
 			current, ok := m.revealProgress[msg.slideIndex]
-			total := msg.config.totalItems()
+			total := msg.slide.reveal.totalItems()
 			minVisible := 0
 			if total > 0 {
 				minVisible = 1
@@ -429,11 +408,148 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case slidesRearrangedMsg:
+		if msg.err != nil {
+			m.notification = fmt.Sprintf("Error: %v", msg.err)
+			m.notificationTimer = 3
+			return m, doTick()
+		}
+		// Follow renamed files so the reload keeps reveal progress
+		for i := range m.slides {
+			if path, ok := msg.renames[m.slides[i].path]; ok {
+				m.slides[i].path = path
+			}
+		}
+		m.focusPath = msg.focus
+		return m, loadSlides(m.root, m.deckFile)
+
+	case externalEditorClosedMsg:
+		if msg.err != nil {
+			m.notification = fmt.Sprintf("Editor error: %v", msg.err)
+			m.notificationTimer = 3
+			return m, doTick()
+		}
+		// Reloading goes through slideReloadedMsg, which keeps the reveal
+		// progress of the edited slide
+		return m, reloadSlide(msg.slideIndex, msg.path, msg.section)
+
 	case errMsg:
 		m.err = msg
 		return m, nil
 
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		delta := 0
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			delta = -wheelLines
+		case tea.MouseButtonWheelDown:
+			delta = wheelLines
+		default:
+			return m, nil
+		}
+		if m.output != nil {
+			_, rows := m.outputPanelSize()
+			m.output.scrollBy(-delta, rows)
+			return m, nil
+		}
+		if m.showOverview || m.showEditor {
+			return m, nil
+		}
+		return m.scrollSlide(delta)
+
 	case tea.KeyMsg:
+		// Any key but Ctrl+C skips to the end of a code morph
+		if m.morph != nil && msg.String() != "ctrl+c" {
+			m.morph = nil
+			return m, nil
+		}
+		if m.confirmDelete {
+			m.confirmDelete = false
+			m.notification = ""
+			m.notificationTimer = 0
+			if msg.String() == "y" {
+				return m, rearrangeSlides(m.root, m.deckFile, m.currentPath(), func(paths []string, index int) (string, map[string]string, error) {
+					renames, err := deleteSlide(paths, index)
+					return "", renames, err
+				})
+			}
+			return m, nil
+		}
+
+		if m.pendingCommand != "" {
+			command := m.pendingCommand
+			m.pendingCommand = ""
+			m.notification = ""
+			m.notificationTimer = 0
+			if msg.String() == "y" {
+				return m.runCommand(command)
+			}
+			return m, nil
+		}
+
+		if m.showOverview {
+			return m.updateOverview(msg)
+		}
+
+		if m.prompting {
+			return m.updatePrompt(msg)
+		}
+
+		if m.output != nil {
+			_, rows := m.outputPanelSize()
+			switch msg.String() {
+			case "x":
+				if m.output.running {
+					m.output.run.kill()
+				}
+				return m, nil
+			case "esc":
+				if m.output.running {
+					m.output.run.kill()
+				}
+				m.output.run.close()
+				m.output = nil
+				return m, nil
+			case "up", "k":
+				m.output.scrollBy(1, rows)
+				return m, nil
+			case "down", "j":
+				m.output.scrollBy(-1, rows)
+				return m, nil
+			case "pgup":
+				m.output.scrollBy(rows, rows)
+				return m, nil
+			case "pgdown":
+				m.output.scrollBy(-rows, rows)
+				return m, nil
+			case "home":
+				m.output.scrollBy(len(m.output.allLines()), rows)
+				return m, nil
+			case "end":
+				m.output.scroll = 0
+				return m, nil
+			}
+		}
+
+		// Shift plus a command hotkey runs the command instead of copying it
+		if key := msg.String(); len(key) == 1 && key >= "A" && key <= "Z" && strings.Contains(commandKeys, strings.ToLower(key)) {
+			index := strings.Index(commandKeys, strings.ToLower(key))
+			if m.currentSlide < len(m.slides) && index < len(m.slides[m.currentSlide].commands) {
+				command := m.slides[m.currentSlide].commands[index]
+				if !m.config.Run.allows(command) {
+					m.pendingCommand = command
+					m.notification = fmt.Sprintf("Run %s? Press 'y' to confirm, any other key to cancel", command)
+					m.notificationTimer = 10
+					return m, doTick()
+				}
+				return m.runCommand(command)
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
@@ -441,6 +557,37 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "q":
 			return m, tea.Quit
 
+		case "tab":
+			if len(m.slides) > 0 {
+				m.openOverview()
+			}
+			return m, nil
+
+		case ":", "/":
+			if len(m.slides) == 0 {
+				return m, nil
+			}
+			m.prompting = true
+			m.prompt = newPrompt(msg.String())
+			return m, textinput.Blink
+
+		case "n":
+			return m.nextResult(m.currentSlide, 1)
+
+		case "N":
+			return m.nextResult(m.currentSlide, -1)
+
+		case "esc":
+			m.searchQuery = ""
+			m.searchResults = nil
+			return m, nil
+
+		case "pgdown":
+			return m.scrollSlide(max(m.contentHeight(m.currentSlide)-2, 1))
+
+		case "pgup":
+			return m.scrollSlide(-max(m.contentHeight(m.currentSlide)-2, 1))
+
 		case "w":
 			// Handle timer start/pause only if timer is configured
 			if m.timerDuration > 0 {
@@ -469,6 +616,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case "p":
+			// The ninth command's hotkey, when the slide has one
+			if cmd, ok := copyHotkey(&m, "p"); ok {
+				return m, cmd
+			}
 			// Handle timer reset confirmation
 			if m.timerDuration > 0 {
 				if m.waitingForReset {
@@ -494,52 +645,67 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.notificationTimer = 2
 				return m, doTick()
 			}
+			// Otherwise the fifth command's hotkey
+			if cmd, ok := copyHotkey(&m, "y"); ok {
+				return m, cmd
+			}
 			return m, nil
 
 		case "e":
 			if len(m.slides) == 0 || m.currentSlide < 0 || m.currentSlide >= len(m.slides) {
 				return m, nil
 			}
-			editor := textarea.New()
-			editor.SetValue(m.slides[m.currentSlide])
-			editor.Placeholder = "Edit slide markdown..."
-			editor.Focus()
-			editor.SetWidth(m.width)
-			editor.SetHeight(m.height - 3)
-			m.editor = editor
-			if m.currentSlide >= 0 && m.currentSlide < len(m.slidePaths) {
-				m.editorPath = m.slidePaths[m.currentSlide]
-			} else {
-				m.editorPath = ""
-			}
+			m.editor = newSlideEditor(m.slides[m.currentSlide], m.revealProgress[m.currentSlide], m.config.themeName(), m.width, m.height)
 			m.showEditor = true
 			return m, textarea.Blink
 
+		case "E":
+			if len(m.slides) == 0 || m.currentPath() == "" {
+				return m, nil
+			}
+			return m, openInEditor(m.currentSlide, m.currentPath(), m.currentSection())
+
+		case "A":
+			// Insert a new slide after the current one and show it
+			if len(m.slides) == 0 {
+				return m, nil
+			}
+			return m, rearrangeSlides(m.root, m.deckFile, m.currentPath(), func(paths []string, index int) (string, map[string]string, error) {
+				return insertSlide(m.root, paths, index+1, "New slide")
+			})
+
+		case "J", "K":
+			// Move the current slide one place later or earlier
+			if len(m.slides) == 0 {
+				return m, nil
+			}
+			delta := 1
+			if msg.String() == "K" {
+				delta = -1
+			}
+			return m, rearrangeSlides(m.root, m.deckFile, m.currentPath(), func(paths []string, index int) (string, map[string]string, error) {
+				return moveSlide(paths, index, index+delta)
+			})
+
+		case "X":
+			if len(m.slides) == 0 || m.currentPath() == "" {
+				return m, nil
+			}
+			m.confirmDelete = true
+			m.notification = fmt.Sprintf("Press 'y' to delete %s, any other key to cancel", filepath.Base(m.currentPath()))
+			m.notificationTimer = 10
+			return m, doTick()
+
 		case "r":
 			if len(m.slides) > 0 {
-				return m, reloadSlide(m.currentSlide)
+				return m, reloadSlide(m.currentSlide, m.currentPath(), m.currentSection())
 			}
 			return m, nil
 
 		case "d":
 			// Handle first command hotkey
-			if m.currentSlide < len(m.commandBlocks) && len(m.commandBlocks[m.currentSlide]) > 0 {
-				commands := m.commandBlocks[m.currentSlide]
-				if len(commands) > 0 {
-					if err := copyToClipboard(commands[0]); err != nil {
-						m.notification = fmt.Sprintf("Copy error: %v", err)
-					} else {
-						// Truncate command text to fit notification bar
-						displayCmd := commands[0]
-						maxWidth := m.width - 12 // Reserve space for "Copied: " text and padding
-						if len(displayCmd) > maxWidth {
-							displayCmd = displayCmd[:maxWidth-3] + "..."
-						}
-						m.notification = fmt.Sprintf("Copied: %s", displayCmd)
-					}
-					m.notificationTimer = 3
-					return m, doTick()
-				}
+			if cmd, ok := copyHotkey(&m, "d"); ok {
+				return m, cmd
 			}
 			return m, nil
 
@@ -599,29 +765,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 			// Handle command hotkeys (only if current slide has commands)
-			if m.currentSlide < len(m.commandBlocks) && len(m.commandBlocks[m.currentSlide]) > 0 {
-				commands := m.commandBlocks[m.currentSlide]
-				// Map keys to indices (d=0 is handled above, start from f=1)
-				keyMap := map[string]int{
-					"f": 1, "g": 2, "t": 3, "y": 4,
-					"u": 5, "i": 6, "o": 7, "p": 8, "z": 9,
-				}
-
-				if cmdNum, exists := keyMap[msg.String()]; exists && cmdNum < len(commands) {
-					if err := copyToClipboard(commands[cmdNum]); err != nil {
-						m.notification = fmt.Sprintf("Copy error: %v", err)
-					} else {
-						// Truncate command text to fit notification bar
-						displayCmd := commands[cmdNum]
-						maxWidth := m.width - 12 // Reserve space for "Copied: " text and padding
-						if len(displayCmd) > maxWidth {
-							displayCmd = displayCmd[:maxWidth-3] + "..."
-						}
-						m.notification = fmt.Sprintf("Copied: %s", displayCmd)
-					}
-					m.notificationTimer = 3 // Show for 3 seconds
-					return m, doTick()
-				}
+			if cmd, ok := copyHotkey(&m, msg.String()); ok {
+				return m, cmd
 			}
 			return m, nil
 
@@ -653,6 +798,34 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case clipboardWrittenMsg:
+		m.clipboardSequence = ""
+		return m, nil
+
+	case transitionTickMsg:
+		if m.transition == nil || m.transition.id != msg.id {
+			return m, nil
+		}
+		if time.Since(m.transition.lastTick) > frameLate {
+			m.slowLink = true
+		}
+		if m.slowLink || m.transition.progress() >= 1 {
+			m.transition = nil
+			return m, nil
+		}
+		m.transition.lastTick = time.Now()
+		return m, doTransitionTick(msg.id)
+
+	case morphTickMsg:
+		if m.morph == nil || m.morph.id != msg.id {
+			return m, nil
+		}
+		if m.morphProgress(m.morph.slide) >= 1 {
+			m.morph = nil
+			return m, nil
+		}
+		return m, doMorphTick(msg.id)
+
 	case timerTickMsg:
 		if m.timerDuration > 0 && m.timerTicking {
 			// Update timer progress regardless of running state
@@ -670,11 +843,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// currentPath returns the file the current slide was loaded from, or "" if
+// it is unknown.
+func (m model) currentPath() string {
+	if m.currentSlide >= 0 && m.currentSlide < len(m.slides) {
+		return m.slides[m.currentSlide].path
+	}
+	return ""
+}
+
+// currentSection returns the section of currentPath holding the current
+// slide, or -1 when the slide is the whole file.
+func (m model) currentSection() int {
+	if m.currentSlide >= 0 && m.currentSlide < len(m.slides) {
+		return m.slides[m.currentSlide].section
+	}
+	return -1
+}
+
 func adjustReveal(m *model, slideIndex, delta int) bool {
-	if slideIndex < 0 || slideIndex >= len(m.revealConfigs) {
+	if slideIndex < 0 || slideIndex >= len(m.slides) {
 		return false
 	}
-	cfg := m.revealConfigs[slideIndex]
+	cfg := m.slides[slideIndex].reveal
 	total := cfg.totalItems()
 	if m.revealProgress == nil {
 		m.revealProgress = make(map[int]int)
@@ -730,16 +921,19 @@ func clampInt(value, min, max int) int {
 	return value
 }
 
-
-func applyReveal(content string, cfg revealConfig, count int) string {
+// applyReveal returns content as shown at reveal step count: fragments not
+// yet revealed are left out, the next one replaced by a placeholder, or with
+// dim kept in place for dimHidden to grey out once rendered. Code morphs of
+// step count are shown progress of the way through their animation.
+func applyReveal(content string, cfg revealConfig, count int, dim bool, progress float64) string {
 	total := cfg.totalItems()
 	if total == 0 && len(cfg.directiveLines) == 0 {
 		return content
 	}
 	lines := strings.Split(content, "\n")
-	hide := make(map[int]struct{}, len(cfg.directiveLines))
+	directives := make(map[int]struct{}, len(cfg.directiveLines))
 	for _, idx := range cfg.directiveLines {
-		hide[idx] = struct{}{}
+		directives[idx] = struct{}{}
 	}
 	visible := count
 	if visible < 0 {
@@ -751,33 +945,70 @@ func applyReveal(content string, cfg revealConfig, count int) string {
 	if visible > len(cfg.items) {
 		visible = len(cfg.items)
 	}
+	hide := make(map[int]struct{})
 	for _, item := range cfg.items[visible:] {
 		for _, idx := range item {
 			hide[idx] = struct{}{}
 		}
 	}
-	filtered := make([]string, 0, len(lines)+1)
-	for i, line := range lines {
-		if _, hidden := hide[i]; hidden {
+	placeholder := -1
+	if visible < len(cfg.items) && len(cfg.items[visible]) > 0 {
+		placeholder = cfg.items[visible][0]
+	}
+	dimCode := highlightCode(lines, cfg.highlights, visible)
+	morphAt := make(map[int]codeMorph, len(cfg.morphs))
+	for _, morph := range cfg.morphs {
+		morphAt[morph.start] = morph
+	}
+	filtered := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if morph, ok := morphAt[i]; ok {
+			switch {
+			case visible < morph.step:
+				filtered = append(filtered, morph.frame(0)...)
+			case visible > morph.step:
+				filtered = append(filtered, morph.frame(1)...)
+			default:
+				filtered = append(filtered, morph.frame(progress)...)
+			}
+			i = morph.end
 			continue
 		}
-		filtered = append(filtered, line)
-	}
-	if total > 0 && visible < len(cfg.items) {
-		nextIndices := cfg.items[visible]
-		if len(nextIndices) > 0 && nextIndices[0] < len(lines) {
-			filtered = append(filtered, ellipsisLine(lines[nextIndices[0]]))
-		} else {
-			filtered = append(filtered, "...")
+		if _, directive := directives[i]; directive {
+			continue
+		}
+		if dimCode[i] {
+			line = dimLine(line)
+		} else if len(cfg.highlights) > 0 && isFenceLine(line) {
+			line = stripHighlightGroups(line)
+		}
+		if _, hidden := hide[i]; hidden && strings.TrimSpace(line) != "" {
+			// Blank lines stay, so what follows isn't run into a placeholder
+			switch {
+			case dim:
+				filtered = append(filtered, dimLine(line))
+			case i == placeholder:
+				if ellipsis := ellipsisLine(line); ellipsis != "" {
+					filtered = append(filtered, ellipsis)
+				}
+			}
+			continue
 		}
+		filtered = append(filtered, line)
 	}
 	return strings.Join(filtered, "\n")
 }
 
+// ellipsisLine returns the placeholder for a fragment starting with line:
+// "..." under the same list marker, or nothing for a table row, which
+// would break the table.
 func ellipsisLine(line string) string {
 	trimmed := strings.TrimLeft(line, " 	")
 	indent := line[:len(line)-len(trimmed)]
 	switch {
+	case strings.HasPrefix(trimmed, "|"):
+		return ""
 	case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "), strings.HasPrefix(trimmed, "+ "):
 		return indent + trimmed[:2] + "..."
 	default:
@@ -826,15 +1057,10 @@ func updateTimerProgress(m *model) tea.Cmd {
 	return m.timerProgress.SetPercent(percentage)
 }
 
-func copyToClipboard(text string) error {
-	cmd := exec.Command("pbcopy")
-	cmd.Stdin = strings.NewReader(text)
-	return cmd.Run()
-}
+var commandBlockRe = regexp.MustCompile("(?s)```commands\\s*\\n.*?\\n```")
 
 func stripCommandBlocks(content string) string {
-	re := regexp.MustCompile("(?s)```commands\\s*\\n.*?\\n```")
-	return re.ReplaceAllString(content, "")
+	return commandBlockRe.ReplaceAllString(content, "")
 }
 
 func parseCommandBlocks(content string) []string {
@@ -858,52 +1084,95 @@ func parseCommandBlocks(content string) []string {
 	return commands
 }
 
+// analyzeReveal finds the slide's reveal directives and the fragments each
+// one reveals, grouped into steps: fragments appear in the order of their
+// directives unless a directive gives them a place such as ":reveal 3:", and
+// fragments given the same place appear together. A list shows its first
+// item straight away; other fragments start hidden.
 func analyzeReveal(content string) revealConfig {
 	lines := strings.Split(content, "\n")
-	var directive []int
-	var items [][]int
+	morphs, directive := findMorphs(lines)
+	morphAt := make(map[int]codeMorph, len(morphs))
+	for _, morph := range morphs {
+		morphAt[morph.start] = morph
+	}
+	var fragments []revealFragment
+	order := 1
+	add := func(found []revealFragment) {
+		for _, f := range found {
+			f.order = order
+			fragments = append(fragments, f)
+			order++
+		}
+	}
+	var fence codeFence
 
 	for i := 0; i < len(lines); {
-		if strings.TrimSpace(lines[i]) != ":reveal:" {
+		match := revealDirectiveRe.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if morph, ok := morphAt[i]; ok && !fence.inside() {
+			add([]revealFragment{{morph: &morph}})
+			i = morph.end + 1
+			continue
+		}
+		if match == nil || fence.inside() {
+			if fence.step(lines[i]) && fence.inside() {
+				add(highlightFragments(lines, i))
+			}
 			i++
 			continue
 		}
 		directive = append(directive, i)
+		if match[1] != "" {
+			order, _ = strconv.Atoi(match[1])
+		}
 		i++
-		for i < len(lines) {
-			trimmed := strings.TrimSpace(lines[i])
-			if trimmed == "" {
-				i++
-				continue
-			}
-			if !isListItem(lines[i]) {
-				break
-			}
-			itemIndices := []int{i}
+		for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
 			i++
-			for i < len(lines) {
-				line := lines[i]
-				trimmedLine := strings.TrimSpace(line)
-				if trimmedLine == "" {
-					itemIndices = append(itemIndices, i)
-					i++
-					break
-				}
-				if isListItem(line) {
-					break
-				}
-				if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "	") {
-					itemIndices = append(itemIndices, i)
-					i++
-					continue
-				}
-				break
+		}
+		start := i
+		var found []revealFragment
+		found, i = revealFragments(lines, i)
+		add(found)
+		// Code blocks revealed can still step through highlighted lines
+		var open codeFence
+		for j := start; j < i; j++ {
+			if open.step(lines[j]) && open.inside() {
+				add(highlightFragments(lines, j))
 			}
-			items = append(items, itemIndices)
 		}
 	}
 
-	return revealConfig{directiveLines: directive, items: items}
+	sort.SliceStable(fragments, func(a, b int) bool { return fragments[a].order < fragments[b].order })
+	first := 1
+	if len(fragments) > 0 && !fragments[0].immediate {
+		// Step 1 shows the slide before its first fragment
+		first = 2
+	}
+	var items [][]int
+	var highlights []codeHighlight
+	morphs = nil
+	for k, f := range fragments {
+		if k > 0 && f.order == fragments[k-1].order {
+			items[len(items)-1] = append(items[len(items)-1], f.lines...)
+		} else {
+			items = append(items, append([]int{}, f.lines...))
+		}
+		if f.highlight != nil {
+			h := *f.highlight
+			h.step = len(items) - 1 + first
+			highlights = append(highlights, h)
+		}
+		if f.morph != nil {
+			morph := *f.morph
+			morph.step = len(items) - 1 + first
+			morphs = append(morphs, morph)
+		}
+	}
+	if first == 2 {
+		items = append([][]int{{}}, items...)
+	}
+	sort.Ints(directive)
+	return revealConfig{directiveLines: directive, items: items, highlights: highlights, morphs: morphs}
 }
 
 func isListItem(line string) bool {
@@ -929,31 +1198,60 @@ func isListItem(line string) bool {
 	return false
 }
 
+// commandKeys are the hotkeys of a slide's commands, in order. The key
+// copies the command; with Shift it runs it.
+const commandKeys = "dfgtyuiopz"
+
+// copyHotkey copies the command of the current slide bound to key, and
+// reports whether the slide has one.
+func copyHotkey(m *model, key string) (tea.Cmd, bool) {
+	index := strings.Index(commandKeys, key)
+	if index < 0 || m.currentSlide >= len(m.slides) || index >= len(m.slides[m.currentSlide].commands) {
+		return nil, false
+	}
+	return copyCommand(m, m.slides[m.currentSlide].commands[index]), true
+}
+
+// runCommand starts command in the output panel, unless one is still running.
+func (m model) runCommand(command string) (tea.Model, tea.Cmd) {
+	if m.output != nil && m.output.running {
+		m.notification = "A command is already running; press 'x' to kill it"
+		m.notificationTimer = 3
+		return m, doTick()
+	}
+	m.commandRuns++
+	cols, rows := m.outputPanelSize()
+	run, err := startCommand(m.commandRuns, command, m.root, m.config.Run, cols, rows)
+	if err != nil {
+		m.notification = fmt.Sprintf("Run error: %v", err)
+		m.notificationTimer = 3
+		return m, doTick()
+	}
+	m.output = &outputPanel{command: command, run: run, running: true, status: "running"}
+	return m, run.wait()
+}
+
 func renderCommandHotkeys(commands []string, width int) []string {
 	if len(commands) == 0 {
 		return []string{}
 	}
 
-	keyLabels := []string{"d", "f", "g", "t", "y", "u", "i", "o", "p", "z"}
-
 	var hotkeyLines []string
 	for i, cmd := range commands {
-		if i >= len(keyLabels) { // Only show first 10 commands
+		if i >= len(commandKeys) { // Only show first 10 commands
 			break
 		}
 		// Truncate long commands to fit width
 		displayCmd := cmd
-		maxCmdWidth := width - 10 // Reserve space for key and padding
-		if len(displayCmd) > maxCmdWidth {
-			displayCmd = displayCmd[:maxCmdWidth-3] + "..."
-		}
+		maxCmdWidth := max(width-10, 3) // Reserve space for key and padding
+		displayCmd = ansi.Truncate(displayCmd, maxCmdWidth, "...")
 
 		// Style the key with darker background
 		keyStyle := lipgloss.NewStyle().
 			Background(lipgloss.Color("#1A602C")).
 			Foreground(lipgloss.Color("#FFFFFF")).
 			Padding(0, 1).
-			Render(keyLabels[i])
+			Render(commandKeys[i : i+1])
 
 		hotkey := fmt.Sprintf("%s %s", keyStyle, displayCmd)
 
@@ -971,37 +1269,47 @@ func renderCommandHotkeys(commands []string, width int) []string {
 	return hotkeyLines
 }
 
-func (m model) View() string {
-	if m.showEditor {
-		editorView := m.editor.View()
+// slideMarkdown returns the markdown of s as the audience sees it at the
+// given reveal step, with command blocks and speaker notes stripped.
+func slideMarkdown(s slide, step int) string {
+	return slideMarkdownAt(s, step, 1)
+}
 
-		pathLabel := m.editorPath
-		if pathLabel == "" {
-			pathLabel = "unsaved slide"
-		} else {
-			pathLabel = filepath.Base(pathLabel)
-		}
+// slideMarkdownAt is slideMarkdown with the step's code morphs progress of
+// the way through their animation.
+func slideMarkdownAt(s slide, step int, progress float64) string {
+	return expandBigText(slideTextAt(s, step, progress), filepath.Dir(s.path))
+}
 
-		helpLines := []string{pathLabel, "esc to close - ctrl+s to save & exit"}
-		if m.err != nil {
-			helpLines = append(helpLines, fmt.Sprintf("error: %v", m.err))
-		}
+// slideText is slideMarkdown before big text is drawn, for searching.
+func slideText(s slide, step int) string {
+	return slideTextAt(s, step, 1)
+}
 
-		helpText := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#94A3B8")).
-			Background(lipgloss.Color("#000000")).
-			Width(m.width).
-			Align(lipgloss.Left).
-			Render(strings.Join(helpLines, " | "))
+func slideTextAt(s slide, step int, progress float64) string {
+	content := applyReveal(s.content, s.reveal, step, s.dimsHidden(), progress)
+	content = stripCommandBlocks(content)
+	return stripNotes(content)
+}
 
-		statusBar := lipgloss.NewStyle().
-			Background(lipgloss.Color("#1E3A8A")).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Width(m.width).
-			Padding(0, 1).
-			Render("EDIT MODE")
+// slideArea renders slide index as it fills the height lines above the
+// bars: fitted, scrolled to where the presenter left it, and padded. The
+// prelude must be written ahead of the lines for their images to show.
+func (m model) slideArea(index, height int) ([]string, string) {
+	slideLines, prelude := m.renderSlide(index)
+	lines, _ := scrollWindow(slideLines, height, m.scrollOffsets[index])
+	if _, vertical := m.centering(index); vertical && len(lines) < height {
+		lines = append(make([]string, (height-len(lines))/2), lines...)
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return lines, prelude
+}
 
-		return lipgloss.JoinVertical(lipgloss.Left, statusBar, editorView, helpText)
+func (m model) View() string {
+	if m.showEditor {
+		return m.editor.view(m.width, m.height, m.err)
 	}
 
 	if m.err != nil {
@@ -1012,87 +1320,83 @@ func (m model) View() string {
 		return "Loading slides...\n\nPress 'q' to quit."
 	}
 
-	// Render current slide with glamour
-	slideContent := m.slides[m.currentSlide]
-	if m.currentSlide < len(m.revealConfigs) {
-		slideContent = applyReveal(slideContent, m.revealConfigs[m.currentSlide], m.revealProgress[m.currentSlide])
-	}
-	// Strip command blocks from rendered content
-	slideContent = stripCommandBlocks(slideContent)
-	rendered, err := m.renderer.Render(slideContent)
-	if err != nil {
-		rendered = "Error rendering markdown: " + err.Error()
+	if m.showOverview {
+		return m.overviewView()
 	}
 
-	// Calculate available height for content (reserve lines for bottom bars)
-	contentHeight := m.height - 2 // status + progress
-	var commandHotkeyLines []string
-	if m.currentSlide < len(m.commandBlocks) && len(m.commandBlocks[m.currentSlide]) > 0 {
-		commandHotkeyLines = renderCommandHotkeys(m.commandBlocks[m.currentSlide], m.width)
-		contentHeight -= len(commandHotkeyLines) // reserve lines for each command hotkey
-	}
-	if m.notification != "" {
-		contentHeight-- // additional line for notification
-	}
-	if m.timerDuration > 0 {
-		contentHeight -= 2 // timer display + timer progress bar
-	}
+	// Render current slide with glamour
+	current := m.slides[m.currentSlide]
+	commandHotkeyLines := renderCommandHotkeys(current.commands, m.width)
 
-	// Split rendered content into lines and fit to available height
-	lines := strings.Split(strings.TrimRight(rendered, "\n"), "\n")
-	if len(lines) > contentHeight {
-		lines = lines[:contentHeight]
+	contentHeight := m.contentHeight(m.currentSlide)
+	lines, prelude := m.slideArea(m.currentSlide, contentHeight)
+	if t := m.transition; t != nil && t.slide == m.currentSlide {
+		lines = t.frame(lines, m.width, t.progress())
+	} else if len(lines) > 0 {
+		lines[0] = prelude + lines[0]
 	}
 
-	// Pad content to fill the available height
-	content := strings.Join(lines, "\n")
-	contentLines := len(lines)
-	if contentLines < contentHeight {
-		padding := strings.Repeat("\n", contentHeight-contentLines)
-		content += padding
+	// The output of a running command covers the lower part of the slide
+	if m.output != nil && contentHeight > 0 {
+		_, rows := m.outputPanelSize()
+		panelHeight := min(rows+2, contentHeight)
+		panel := strings.Split(m.output.view(m.width, panelHeight), "\n")
+		copy(lines[contentHeight-panelHeight:], panel)
 	}
+	content := m.clipboardSequence + strings.Join(lines, "\n")
 
 	// Get the animated gradient progress bar
 	progressBar := m.progress.View()
+	if m.static {
+		progressBar = m.progress.ViewAs(m.progress.Percent())
+	}
 
 	// Create three-section status line with chevrons
 	slideInfo := fmt.Sprintf("Slide %d/%d", m.currentSlide+1, len(m.slides))
+	if name := current.sectionName; name != "" {
+		slideInfo += " | " + name
+	}
 
-	titleText := m.title
+	titleText := m.config.Title
 	if titleText == "" {
 		titleText = "Slidetty"
 	}
 
-	authorText := m.author
+	authorText := m.config.Author
 	if authorText == "" {
 		authorText = "Unknown"
 	}
 
 	// Define styles for the three sections
+	colors := m.config.StatusBar.withDefaults()
+	outer := lipgloss.Color(colors.Outer)
+	inner := lipgloss.Color(colors.Inner)
+	foreground := lipgloss.Color(colors.Foreground)
+
 	leftStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#000080")).
-		Foreground(lipgloss.Color("15")).
+		Background(outer).
+		Foreground(foreground).
 		Padding(0, 1)
 
 	centerStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#1E3A8A")).
-		Foreground(lipgloss.Color("15")).
+		Background(inner).
+		Foreground(foreground).
 		PaddingLeft(1).
 		PaddingRight(0)
 
 	rightStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#000080")).
-		Foreground(lipgloss.Color("15")).
+		Background(outer).
+		Foreground(foreground).
 		Padding(0, 1)
 
 	// Chevron styles
 	leftChevronStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#1E3A8A")).
-		Foreground(lipgloss.Color("#000080"))
+		Background(inner).
+		Foreground(outer)
 
 	rightChevronStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#000080")).
-		Foreground(lipgloss.Color("#1E3A8A"))
+		Background(outer).
+		Foreground(inner)
 
 	// Calculate section widths (approximate thirds)
 	totalWidth := m.width
@@ -1131,7 +1435,9 @@ func (m model) View() string {
 
 	// Create notification bar if there's a notification
 	var notificationBar string
-	if m.notification != "" {
+	if m.prompting {
+		notificationBar = lipgloss.NewStyle().Width(m.width).Render(m.prompt.View())
+	} else if m.notification != "" {
 		notificationBar = lipgloss.NewStyle().
 			Background(lipgloss.Color("#059669")).
 			Foreground(lipgloss.Color("#FFFFFF")).
@@ -1173,6 +1479,9 @@ func (m model) View() string {
 
 		timerInfo := fmt.Sprintf("Timer: %dm | %dm - %s",
 			elapsedMin, remainingMin, status)
+		if budget := current.timeBudget(); budget > 0 {
+			timerInfo += fmt.Sprintf(" | slide budget %s", budget)
+		}
 
 		timerDisplay = lipgloss.NewStyle().
 			Background(lipgloss.Color("#8B4513")).
@@ -1196,6 +1505,9 @@ func (m model) View() string {
 	// Add timer display and progress bar at the very bottom
 	if timerDisplay != "" {
 		timerProgressBar := m.timerProgress.View()
+		if m.static {
+			timerProgressBar = m.timerProgress.ViewAs(m.timerProgress.Percent())
+		}
 		result += "\n" + timerDisplay + "\n" + timerProgressBar
 	}
 
@@ -1271,8 +1583,8 @@ Press 'd' to copy the first command above!`
 
 	// Write example slides
 	slides := map[string]string{
-		"01-welcome.md":  slide1,
-		"02-features.md": slide2,
+		"01-welcome.md":         slide1,
+		"02-features.md":        slide2,
 		"03-getting-started.md": slide3,
 	}
 
@@ -1296,8 +1608,10 @@ Press 'd' to copy the first command above!`
 }
 
 func main() {
+	args := os.Args[1:]
+
 	// Check for init command
-	if len(os.Args) > 1 && os.Args[1] == "init" {
+	if len(args) > 0 && args[0] == "init" {
 		if err := initProject(); err != nil {
 			fmt.Printf("Error initializing project: %v\n", err)
 			os.Exit(1)
@@ -1305,9 +1619,87 @@ func main() {
 		return
 	}
 
+	if len(args) > 0 && args[0] == "export" {
+		if err := runExport(args[1:]); err != nil {
+			fmt.Printf("Error exporting deck: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "ls", "new", "mv", "rm", "renumber":
+			if err := runManage(args[0], args[1:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	if len(args) > 0 && args[0] == "lint" {
+		if err := runLint(args[1:], os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 && args[0] == "render" {
+		if err := runRender(args[1:], os.Stdout); err != nil {
+			fmt.Printf("Error rendering deck: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// "present" is the explicit form of the default command
+	command := "present"
+	if len(args) > 0 && (args[0] == "present" || args[0] == "presenter") {
+		command = args[0]
+		args = args[1:]
+	}
+
+	var target string
+	if len(args) > 0 {
+		target = args[0]
+	}
+	root, deckFile, err := resolveDeck(target)
+	if err != nil {
+		fmt.Printf("Error opening deck: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Watch the deck so edits made elsewhere show up live
+	watcher := newDeckWatcher(root)
+	defer watcher.Close()
+
+	// The presenter console follows an audience window started separately
+	if command == "presenter" {
+		p := tea.NewProgram(newPresenterModel(root, deckFile, watcher), tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			fmt.Printf("Error running program: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Let `slidetty presenter` follow along; presenting works without it
+	server, err := startSyncServer(syncSocketPath(root, deckFile))
+	if err != nil {
+		fmt.Printf("Presenter console unavailable: %v\n", err)
+	}
+	defer server.Close()
+
 	// Run normal slideshow
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	p := tea.NewProgram(initialModel(root, deckFile, watcher, server), tea.WithAltScreen())
+	final, err := p.Run()
+	// Don't leave a demo command running behind the closed presentation
+	if m, ok := final.(model); ok && m.output != nil && m.output.running {
+		m.output.run.kill()
+	}
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// slidePrefixRe matches the ordering prefix of a slide file name, including
+// hand-inserted ones such as the "12a-" in 12a-marking.md.
+var slidePrefixRe = regexp.MustCompile(`^\d+[a-z]?-`)
+
+// slideSlug returns a slide file name without its ordering prefix.
+func slideSlug(name string) string {
+	return slidePrefixRe.ReplaceAllString(name, "")
+}
+
+var nonSlugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a title into a file name stem.
+func slugify(title string) string {
+	slug := strings.Trim(nonSlugRe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if slug == "" {
+		return "slide"
+	}
+	return slug
+}
+
+// deckSlideFiles returns the slide files of a directory deck in order.
+// Slides of a single-file deck are sections of one file and are reordered
+// by editing it, so they are not managed here.
+func deckSlideFiles(root, deckFile string) ([]string, error) {
+	if deckFile != "" {
+		return nil, fmt.Errorf("%s is a single-file deck; move its sections in an editor", deckFile)
+	}
+	if _, ok, err := loadManifest(root); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, fmt.Errorf("the deck's slide order comes from its manifest; edit %s or %s instead", deckConfigFile, orderFile)
+	}
+	return listSlideFiles(root)
+}
+
+// isTracked reports whether git tracks path, so renames and deletions can go
+// through git and show up as such in history.
+func isTracked(path string) bool {
+	if _, err := exec.LookPath("git"); err != nil {
+		return false
+	}
+	cmd := exec.Command("git", "ls-files", "--error-unmatch", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	return cmd.Run() == nil
+}
+
+// renameFile renames src to dst with git mv when git tracks src.
+func renameFile(src, dst string) error {
+	if isTracked(src) {
+		cmd := exec.Command("git", "mv", filepath.Base(src), filepath.Base(dst))
+		cmd.Dir = filepath.Dir(src)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git mv %s: %s", filepath.Base(src), strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return os.Rename(src, dst)
+}
+
+// removeFile deletes path with git rm when git tracks it. Git refuses to
+// delete a file with uncommitted changes, and the error says so.
+func removeFile(path string) error {
+	if isTracked(path) {
+		cmd := exec.Command("git", "rm", "-q", filepath.Base(path))
+		cmd.Dir = filepath.Dir(path)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git rm %s: %s", filepath.Base(path), strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// renumberSlides gives the files in paths consecutive prefixes (01-, 02-, …)
+// in the order given, keeping the rest of each name. It returns the new
+// paths, and a map from every renamed path to its new one. Files are moved
+// through temporary names, which are not slide names, so that no rename
+// overwrites another slide. If a rename fails, the ones done are undone.
+func renumberSlides(paths []string) ([]string, map[string]string, error) {
+	digits := max(len(strconv.Itoa(len(paths))), 2)
+	renamed := make([]string, len(paths))
+	renames := make(map[string]string)
+	for i, path := range paths {
+		name := fmt.Sprintf("%0*d-%s", digits, i+1, slideSlug(filepath.Base(path)))
+		renamed[i] = filepath.Join(filepath.Dir(path), name)
+		if renamed[i] != path {
+			renames[path] = renamed[i]
+		}
+	}
+	for _, dst := range renames {
+		if _, moving := renames[dst]; moving {
+			continue
+		}
+		if _, err := os.Stat(dst); err == nil {
+			return nil, nil, fmt.Errorf("%s already exists", dst)
+		}
+	}
+
+	type move struct{ from, to string }
+	var done []move
+	rename := func(from, to string) error {
+		if err := renameFile(from, to); err != nil {
+			// Put back what was moved, most recent first
+			for i := len(done) - 1; i >= 0; i-- {
+				renameFile(done[i].to, done[i].from)
+			}
+			return err
+		}
+		done = append(done, move{from, to})
+		return nil
+	}
+
+	temps := make(map[string]string, len(renames))
+	for src := range renames {
+		temp := filepath.Join(filepath.Dir(src), ".renumber-"+filepath.Base(src)+".tmp")
+		if err := rename(src, temp); err != nil {
+			return nil, nil, err
+		}
+		temps[src] = temp
+	}
+	for src, dst := range renames {
+		if err := rename(temps[src], dst); err != nil {
+			return nil, nil, err
+		}
+	}
+	return renamed, renames, nil
+}
+
+// insertSlide creates a slide titled title at position index (0-based) of
+// the deck and renumbers the slides after it. It returns the new file's path.
+func insertSlide(root string, paths []string, index int, title string) (string, map[string]string, error) {
+	index = clampInt(index, 0, len(paths))
+	// The file starts without a prefix; renumbering gives it one
+	path := filepath.Join(root, slugify(title)+".md")
+	if _, err := os.Stat(path); err == nil {
+		return "", nil, fmt.Errorf("%s already exists", path)
+	}
+	if err := os.WriteFile(path, []byte("# "+title+"\n"), 0o644); err != nil {
+		return "", nil, err
+	}
+	ordered := append(append(append([]string{}, paths[:index]...), path), paths[index:]...)
+	renamed, renames, err := renumberSlides(ordered)
+	if err != nil {
+		// The deck is left as it was, so the new slide goes too
+		os.Remove(path)
+		return "", nil, err
+	}
+	return renamed[index], renames, nil
+}
+
+// moveSlide moves the slide at position from to position to (both 0-based)
+// and renumbers the deck. It returns the moved file's new path.
+func moveSlide(paths []string, from, to int) (string, map[string]string, error) {
+	if from < 0 || from >= len(paths) {
+		return "", nil, fmt.Errorf("no slide %d; the deck has %d slides", from+1, len(paths))
+	}
+	to = clampInt(to, 0, len(paths)-1)
+	ordered := append([]string{}, paths...)
+	moved := ordered[from]
+	ordered = append(ordered[:from], ordered[from+1:]...)
+	ordered = append(ordered[:to], append([]string{moved}, ordered[to:]...)...)
+	renamed, renames, err := renumberSlides(ordered)
+	if err != nil {
+		return "", nil, err
+	}
+	return renamed[to], renames, nil
+}
+
+// deleteSlide removes the slide at position index (0-based) and renumbers
+// the slides after it.
+func deleteSlide(paths []string, index int) (map[string]string, error) {
+	if index < 0 || index >= len(paths) {
+		return nil, fmt.Errorf("no slide %d; the deck has %d slides", index+1, len(paths))
+	}
+	if err := removeFile(paths[index]); err != nil {
+		return nil, err
+	}
+	remaining := append(append([]string{}, paths[:index]...), paths[index+1:]...)
+	_, renames, err := renumberSlides(remaining)
+	return renames, err
+}
+
+// slideNumber parses a 1-based slide number argument into an index.
+func slideNumber(arg string, count int) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > count {
+		return 0, fmt.Errorf("%q is not a slide number between 1 and %d", arg, count)
+	}
+	return n - 1, nil
+}
+
+// runManage implements the slide management subcommands: ls, new, mv, rm
+// and renumber. Slide numbers count every slide file, including skipped
+// ones, as listed by `slidetty ls`.
+func runManage(command string, args []string) error {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	var title *string
+	var after *int
+	usage := map[string]string{
+		"ls":       "ls [deck]",
+		"new":      "new [--title T] [--after N] [deck]",
+		"mv":       "mv FROM TO [deck]",
+		"rm":       "rm N [deck]",
+		"renumber": "renumber [deck]",
+	}[command]
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: slidetty %s\n", usage)
+		fs.PrintDefaults()
+	}
+	if command == "new" {
+		title = fs.String("title", "New slide", "title of the new slide")
+		after = fs.Int("after", -1, "insert after slide N (default: at the end)")
+	}
+	fs.Parse(args)
+
+	positional := map[string]int{"mv": 2, "rm": 1}[command]
+	if fs.NArg() < positional || fs.NArg() > positional+1 {
+		fs.Usage()
+		return fmt.Errorf("wrong number of arguments")
+	}
+	root, deckFile, err := resolveDeck(fs.Arg(positional))
+	if err != nil {
+		return err
+	}
+	paths, err := deckSlideFiles(root, deckFile)
+	if err != nil {
+		return err
+	}
+
+	var renames map[string]string
+	switch command {
+	case "ls":
+		for i, path := range paths {
+			fmt.Printf("%3d  %s\n", i+1, filepath.Base(path))
+		}
+		return nil
+	case "new":
+		index := len(paths)
+		if *after >= 0 {
+			index = *after
+		}
+		var path string
+		path, renames, err = insertSlide(root, paths, index, *title)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", path)
+	case "mv":
+		from, err := slideNumber(fs.Arg(0), len(paths))
+		if err != nil {
+			return err
+		}
+		to, err := slideNumber(fs.Arg(1), len(paths))
+		if err != nil {
+			return err
+		}
+		_, renames, err = moveSlide(paths, from, to)
+		if err != nil {
+			return err
+		}
+	case "rm":
+		index, err := slideNumber(fs.Arg(0), len(paths))
+		if err != nil {
+			return err
+		}
+		renames, err = deleteSlide(paths, index)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", paths[index])
+	case "renumber":
+		_, renames, err = renumberSlides(paths)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, path := range paths {
+		if dst, ok := renames[path]; ok {
+			fmt.Printf("Renamed %s -> %s\n", filepath.Base(path), filepath.Base(dst))
+		}
+	}
+	return nil
+}
+
+// slidesRearrangedMsg reports a change to the deck's files made from the
+// presentation. renames maps old slide paths to new ones and focus is the
+// slide to show once the deck reloads.
+type slidesRearrangedMsg struct {
+	renames map[string]string
+	focus   string
+	err     error
+}
+
+// rearrangeSlides runs a slide management operation on the current deck
+// from the presentation. op receives the deck's slide files and the index
+// of path among them.
+func rearrangeSlides(root, deckFile, path string, op func(paths []string, index int) (focus string, renames map[string]string, err error)) tea.Cmd {
+	return func() tea.Msg {
+		paths, err := deckSlideFiles(root, deckFile)
+		if err != nil {
+			return slidesRearrangedMsg{err: err}
+		}
+		index := -1
+		for i, p := range paths {
+			if p == path {
+				index = i
+			}
+		}
+		if index < 0 {
+			return slidesRearrangedMsg{err: fmt.Errorf("%s is not a slide of the deck", path)}
+		}
+		focus, renames, err := op(paths, index)
+		return slidesRearrangedMsg{renames: renames, focus: focus, err: err}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeSlides creates files with the given names in a new directory and
+// returns their paths.
+func writeSlides(t *testing.T, names ...string) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("# "+name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return dir, paths
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRenumberSlides(t *testing.T) {
+	dir, paths := writeSlides(t, "01-intro.md", "02-middle.md", "03-end.md")
+	// Swap the first and last slides
+	renamed, renames, err := renumberSlides([]string{paths[2], paths[1], paths[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"01-end.md", "02-middle.md", "03-intro.md"}
+	if got := dirNames(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %q, want %q", got, want)
+	}
+	if filepath.Base(renamed[0]) != "01-end.md" || filepath.Base(renamed[2]) != "03-intro.md" {
+		t.Errorf("renamed = %q", renamed)
+	}
+	if len(renames) != 2 || renames[paths[0]] != renamed[2] {
+		t.Errorf("renames = %q", renames)
+	}
+	content, err := os.ReadFile(renamed[0])
+	if err != nil || string(content) != "# 03-end.md\n" {
+		t.Errorf("01-end.md holds %q, %v", content, err)
+	}
+}
+
+func TestRenumberSlidesWidensPrefixes(t *testing.T) {
+	var names []string
+	for i := 0; i < 100; i++ {
+		names = append(names, slugify("slide")+string(rune('a'+i%26))+string(rune('a'+i/26))+".md")
+	}
+	dir, paths := writeSlides(t, names...)
+	renamed, _, err := renumberSlides(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filepath.Base(renamed[0]); got != "001-slideaa.md" {
+		t.Errorf("first slide renamed to %q", got)
+	}
+	if got := len(dirNames(t, dir)); got != 100 {
+		t.Errorf("%d files after renumbering, want 100", got)
+	}
+}
+
+func TestRenumberSlidesRefusesToOverwrite(t *testing.T) {
+	dir, paths := writeSlides(t, "intro.md", "01-intro.md")
+	// intro.md would become 01-intro.md, which isn't part of the renumbering
+	if _, _, err := renumberSlides(paths[:1]); err == nil {
+		t.Fatal("renumberSlides overwrote 01-intro.md")
+	}
+	if got, want := dirNames(t, dir), []string{"01-intro.md", "intro.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %q, want %q", got, want)
+	}
+}
+
+func TestInsertSlideCleansUpAfterFailure(t *testing.T) {
+	dir, paths := writeSlides(t, "01-intro.md", "02-end.md", "03-end.md")
+	// Inserting first would move 02-end.md onto 03-end.md, which isn't part
+	// of the deck
+	if _, _, err := insertSlide(dir, paths[:2], 0, "New"); err == nil {
+		t.Fatal("insertSlide overwrote 03-end.md")
+	}
+	if got, want := dirNames(t, dir), []string{"01-intro.md", "02-end.md", "03-end.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %q, want %q", got, want)
+	}
+}
+
+func TestRenumberTempNamesAreNotSlides(t *testing.T) {
+	if isSlideFile(".renumber-01-intro.md.tmp") {
+		t.Error("a temporary name from renumbering would load as a slide")
+	}
+}
+
+func TestRemoveFileKeepsUncommittedEdits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir, paths := writeSlides(t, "01-intro.md")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "slides"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s", args[0], out)
+		}
+	}
+	if err := os.WriteFile(paths[0], []byte("# Edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeFile(paths[0]); err == nil {
+		t.Fatal("removeFile deleted a slide with uncommitted changes")
+	}
+	if content, err := os.ReadFile(paths[0]); err != nil || string(content) != "# Edited\n" {
+		t.Errorf("slide holds %q, %v after a refused removal", content, err)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// orderFile lists a deck's slides in order, as a plain-text alternative to
+// the sections and slides lists of deck.yaml.
+const orderFile = "_order"
+
+// manifestItem is one entry of a slide list: a slide file, or another deck
+// (a directory or single-file deck) whose slides are all included.
+type manifestItem struct {
+	File    string `yaml:"file"`
+	Include string `yaml:"include"`
+}
+
+// UnmarshalYAML accepts a bare string as a slide file.
+func (i *manifestItem) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		i.File = node.Value
+		return nil
+	}
+	type plain manifestItem
+	return node.Decode((*plain)(i))
+}
+
+// manifestSection is a named run of slides.
+type manifestSection struct {
+	Name   string         `yaml:"name"`
+	Slides []manifestItem `yaml:"slides"`
+}
+
+// deckManifest is an explicit slide order. Slides listed before any named
+// section belong to no section.
+type deckManifest struct {
+	Slides   []manifestItem    `yaml:"slides"`
+	Sections []manifestSection `yaml:"sections"`
+}
+
+func (m deckManifest) sections() []manifestSection {
+	if len(m.Slides) == 0 {
+		return m.Sections
+	}
+	return append([]manifestSection{{Slides: m.Slides}}, m.Sections...)
+}
+
+// parseOrderFile reads the _order format: one slide file per line,
+// "[Name]" lines starting a section, "include PATH" lines including another
+// deck, and "#" lines as comments.
+func parseOrderFile(content string) deckManifest {
+	var m deckManifest
+	current := &m.Slides
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			m.Sections = append(m.Sections, manifestSection{Name: strings.TrimSpace(line[1 : len(line)-1])})
+			current = &m.Sections[len(m.Sections)-1].Slides
+		case strings.HasPrefix(line, "include "):
+			*current = append(*current, manifestItem{Include: strings.TrimSpace(strings.TrimPrefix(line, "include "))})
+		default:
+			*current = append(*current, manifestItem{File: line})
+		}
+	}
+	return m
+}
+
+// loadManifest reads the slide order of the deck in root from deck.yaml,
+// or failing that from _order. ok is false when the deck has neither and
+// slides are ordered by file name.
+func loadManifest(root string) (m deckManifest, ok bool, err error) {
+	content, err := os.ReadFile(filepath.Join(root, deckConfigFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return m, false, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(content, &m); err != nil {
+			return m, false, fmt.Errorf("%s: %v", deckConfigFile, err)
+		}
+		if len(m.sections()) > 0 {
+			return m, true, nil
+		}
+	}
+
+	content, err = os.ReadFile(filepath.Join(root, orderFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return deckManifest{}, false, nil
+	}
+	if err != nil {
+		return deckManifest{}, false, err
+	}
+	return parseOrderFile(string(content)), true, nil
+}
+
+// manifestSlides loads the slides m lists, with paths relative to root.
+// Included slides keep the section names of their own deck's manifest and
+// otherwise take the name of the section including them. including holds
+// the decks already being loaded, to catch include cycles.
+func manifestSlides(root string, m deckManifest, including []string) ([]slide, error) {
+	var slides []slide
+	for _, section := range m.sections() {
+		for _, item := range section.Slides {
+			if item.Include == "" {
+				path := filepath.Join(root, item.File)
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return nil, err
+				}
+				s, _ := fileSlide(path, string(content))
+				s.sectionName = section.Name
+				slides = append(slides, s)
+				continue
+			}
+
+			includeRoot, includeFile, err := resolveDeck(filepath.Join(root, item.Include))
+			if err != nil {
+				return nil, fmt.Errorf("include %s: %v", item.Include, err)
+			}
+			if slices.Contains(including, deckID(includeRoot, includeFile)) {
+				return nil, fmt.Errorf("include %s: the decks include each other", item.Include)
+			}
+			included, _, err := deckSlides(includeRoot, includeFile, including)
+			if err != nil {
+				return nil, fmt.Errorf("include %s: %v", item.Include, err)
+			}
+			for _, s := range included {
+				if s.sectionName == "" {
+					s.sectionName = section.Name
+				}
+				slides = append(slides, s)
+			}
+		}
+	}
+	return slides, nil
+}
+
+// deckID identifies a deck by its absolute location.
+func deckID(root, deckFile string) string {
+	if deckFile != "" {
+		root = deckFile
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		return abs
+	}
+	return root
+}
+
+// deckDirs returns the directories the deck in root reads files from,
+// besides root: those of slide files its manifest lists elsewhere, and
+// those of the decks it includes, directly or not.
+func deckDirs(root string) []string {
+	var dirs []string
+	seen := map[string]bool{deckID(root, ""): true}
+	add := func(dir string) bool {
+		if seen[deckID(dir, "")] {
+			return false
+		}
+		seen[deckID(dir, "")] = true
+		dirs = append(dirs, dir)
+		return true
+	}
+	var visit func(root string)
+	visit = func(root string) {
+		m, ok, err := loadManifest(root)
+		if err != nil || !ok {
+			return
+		}
+		for _, section := range m.sections() {
+			for _, item := range section.Slides {
+				if item.Include == "" {
+					add(filepath.Dir(filepath.Join(root, item.File)))
+					continue
+				}
+				includeRoot, includeFile, err := resolveDeck(filepath.Join(root, item.Include))
+				if err == nil && add(includeRoot) && includeFile == "" {
+					visit(includeRoot)
+				}
+			}
+		}
+	}
+	visit(root)
+	return dirs
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Ways a code morph animates from the first code to the second.
+const (
+	morphType = "type" // removed lines vanish, then new code is typed in
+	morphFade = "fade" // removed lines fade out, then new lines fade in
+)
+
+// defaultTypeSpeed is how many characters per second morphs type when the
+// deck doesn't set typing_speed.
+const defaultTypeSpeed = 40
+
+// fadeDuration is how long a fading morph takes.
+const fadeDuration = 800 * time.Millisecond
+
+// morphDirectiveRe matches the ":morph:" line between two code blocks, which
+// may name how to animate, as in ":morph fade:".
+var morphDirectiveRe = regexp.MustCompile(`^:morph(?:\s+(\w+))?:$`)
+
+// diffFenceRe matches the fence of a code block written as a diff of the
+// code it changes, as in ```diff-go, optionally followed by how to animate.
+var diffFenceRe = regexp.MustCompile("^\\s*(?:```+|~~~+)\\s*diff-(\\S+)(?:\\s+(\\w+))?\\s*$")
+
+// diffLine is a line of a code morph: unchanged (' '), removed ('-') or
+// added ('+').
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// codeMorph is code that changes in one reveal step, from a ```diff-lang
+// block or from the code block before a ":morph:" line to the one after.
+type codeMorph struct {
+	start, end int // lines of the slide the morph replaces
+	language   string
+	style      string
+	lines      []diffLine
+	step       int // reveal step the change happens at
+}
+
+// codeBlock is a fenced code block's lines: the opening fence, the code
+// and the closing fence at end.
+type codeBlock struct {
+	start, end int
+	info       string
+}
+
+// findMorphs returns the code morphs in a slide, and the lines of its
+// ":morph:" directives.
+func findMorphs(lines []string) ([]codeMorph, []int) {
+	var blocks []codeBlock
+	var morphs []codeMorph
+	var directives []int
+	var fence codeFence
+	for i, line := range lines {
+		if fence.step(line) {
+			if fence.inside() {
+				_, _, info := fenceMarker(line)
+				blocks = append(blocks, codeBlock{start: i, end: len(lines) - 1, info: info})
+			} else {
+				blocks[len(blocks)-1].end = i
+			}
+			continue
+		}
+		if fence.inside() || !morphDirectiveRe.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		directives = append(directives, i)
+	}
+
+	for k, block := range blocks {
+		if match := diffFenceRe.FindStringSubmatch(lines[block.start]); match != nil {
+			morph := codeMorph{start: block.start, end: block.end, language: match[1], style: match[2]}
+			for _, line := range lines[block.start+1 : block.end] {
+				op, text := byte(' '), line
+				if line != "" && strings.ContainsRune(" -+", rune(line[0])) {
+					op, text = line[0], line[1:]
+				}
+				morph.lines = append(morph.lines, diffLine{op: op, text: text})
+			}
+			morphs = append(morphs, morph)
+			continue
+		}
+		// A ":morph:" line with only blank lines around it joins two blocks
+		if k == 0 {
+			continue
+		}
+		previous := blocks[k-1]
+		for _, d := range directives {
+			if d < previous.end || d > block.start || !blankBetween(lines, previous.end+1, d) || !blankBetween(lines, d+1, block.start) {
+				continue
+			}
+			match := morphDirectiveRe.FindStringSubmatch(strings.TrimSpace(lines[d]))
+			language, _, _ := strings.Cut(block.info, " ")
+			morphs = append(morphs, codeMorph{
+				start:    previous.start,
+				end:      block.end,
+				language: language,
+				style:    match[1],
+				lines:    diffCode(lines[previous.start+1:previous.end], lines[block.start+1:block.end]),
+			})
+		}
+	}
+	return morphs, directives
+}
+
+// blankBetween reports whether lines[from:to] are all blank.
+func blankBetween(lines []string, from, to int) bool {
+	for _, line := range lines[from:to] {
+		if strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// diffCode returns the lines that turn before into after, keeping the
+// longest run of lines the two have in common.
+func diffCode(before, after []string) []diffLine {
+	// common[i][j] is the longest common subsequence of before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			lines = append(lines, diffLine{op: ' ', text: before[i]})
+			i, j = i+1, j+1
+		case j < len(after) && (i == len(before) || common[i][j+1] >= common[i+1][j]):
+			lines = append(lines, diffLine{op: '+', text: after[j]})
+			j++
+		default:
+			lines = append(lines, diffLine{op: '-', text: before[i]})
+			i++
+		}
+	}
+	return lines
+}
+
+// typed returns the number of characters the morph types.
+func (c codeMorph) typed() int {
+	n := 0
+	for _, line := range c.lines {
+		if line.op == '+' {
+			n += len([]rune(line.text))
+		}
+	}
+	return n
+}
+
+// duration returns how long the morph animates, typing at speed characters
+// per second.
+func (c codeMorph) duration(speed int) time.Duration {
+	if c.style == morphFade {
+		return fadeDuration
+	}
+	d := time.Duration(c.typed()) * time.Second / time.Duration(max(speed, 1))
+	return min(max(d, 200*time.Millisecond), 10*time.Second)
+}
+
+// frame returns the morph as a code block, progress of the way through its
+// animation: 0 is the code before, 1 the code after.
+func (c codeMorph) frame(progress float64) []string {
+	code := []string{"```" + c.language}
+	typing := int(progress * float64(c.typed()))
+	for _, line := range c.lines {
+		switch {
+		case line.op == ' ':
+			code = append(code, line.text)
+		case progress <= 0:
+			if line.op == '-' {
+				code = append(code, line.text)
+			}
+		case progress >= 1:
+			if line.op == '+' {
+				code = append(code, line.text)
+			}
+		case c.style == morphFade:
+			// Removed lines fade out during the first half, added ones in
+			// during the second
+			if (line.op == '-') == (progress < 0.5) {
+				code = append(code, dimLine(line.text))
+			}
+		case line.op == '+' && typing >= 0:
+			text := []rune(line.text)
+			if typing >= len(text) {
+				code = append(code, line.text)
+				typing -= len(text)
+				continue
+			}
+			code = append(code, string(text[:typing])+"▌")
+			typing = -1 // lines after the cursor are still to be typed
+		}
+	}
+	return append(code, "```")
+}
+
+// morphAnimation is a code morph playing out on screen.
+type morphAnimation struct {
+	id       int // tells its ticks from those of morphs it replaced
+	slide    int
+	start    time.Time
+	duration time.Duration
+}
+
+// morphTickMsg moves morph id on a frame.
+type morphTickMsg struct{ id int }
+
+func doMorphTick(id int) tea.Cmd {
+	return tea.Tick(frameInterval, func(t time.Time) tea.Msg {
+		return morphTickMsg{id: id}
+	})
+}
+
+// startMorph starts animating the code morphs of the reveal step the
+// current slide has moved forward to, however it got there, if it has any.
+// slide and from are the slide and step shown before.
+func startMorph(m *model, slide, from int) tea.Cmd {
+	if m.currentSlide != slide || m.currentSlide >= len(m.slides) || m.revealProgress[m.currentSlide] <= from {
+		return nil
+	}
+	s := m.slides[m.currentSlide]
+	step := m.revealProgress[m.currentSlide]
+	var duration time.Duration
+	for _, morph := range s.reveal.morphs {
+		if morph.step == step {
+			duration = max(duration, morph.duration(orDefault(m.config.TypeSpeed, defaultTypeSpeed)))
+		}
+	}
+	if duration == 0 || m.static || m.slowLink || !motionAllowed() {
+		return nil
+	}
+	m.morphs++
+	m.morph = &morphAnimation{id: m.morphs, slide: m.currentSlide, start: time.Now(), duration: duration}
+	return doMorphTick(m.morphs)
+}
+
+// morphProgress returns how far the morph animating on slide index has
+// got, 1 when none is.
+func (m model) morphProgress(index int) float64 {
+	if m.morph == nil || m.morph.slide != index {
+		return 1
+	}
+	return min(float64(time.Since(m.morph.start))/float64(m.morph.duration), 1)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffCode(t *testing.T) {
+	tests := []struct {
+		before, after string
+		want          string // ops and text, one line each
+	}{
+		{"a\nb\nc", "a\nb\nc", " a\n b\n c"},
+		{"a\nb\nc", "a\nx\nc", " a\n+x\n-b\n c"},
+		{"a\nc", "a\nb\nc", " a\n+b\n c"},
+		{"a\nb", "b", "-a\n b"},
+		{"", "x", "+x\n-"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, line := range diffCode(strings.Split(tt.before, "\n"), strings.Split(tt.after, "\n")) {
+			got = append(got, string(line.op)+line.text)
+		}
+		if want := strings.Split(tt.want, "\n"); !reflect.DeepEqual(got, want) {
+			t.Errorf("diffCode(%q, %q) = %q, want %q", tt.before, tt.after, got, want)
+		}
+	}
+}
+
+func TestFindMorphs(t *testing.T) {
+	lines := strings.Split("```go\nx := 1\n```\n\n:morph fade:\n\n```go\nx := 2\n```\n\n```diff-go\n-a\n+b\n```", "\n")
+	morphs, directives := findMorphs(lines)
+	if !reflect.DeepEqual(directives, []int{4}) {
+		t.Errorf("directives = %v", directives)
+	}
+	if len(morphs) != 2 {
+		t.Fatalf("found %d morphs, want 2", len(morphs))
+	}
+	if m := morphs[0]; m.start != 0 || m.end != 8 || m.style != morphFade {
+		t.Errorf("directive morph = %+v", m)
+	}
+	if m := morphs[1]; m.start != 10 || m.language != "go" || len(m.lines) != 2 {
+		t.Errorf("diff morph = %+v", m)
+	}
+}
+
+func TestMorphFrame(t *testing.T) {
+	morph := codeMorph{language: "go", lines: []diffLine{{' ', "a"}, {'-', "b"}, {'+', "cd"}}}
+	tests := map[float64]string{
+		0:   "```go\na\nb\n```",
+		0.5: "```go\na\nc▌\n```",
+		1:   "```go\na\ncd\n```",
+	}
+	for progress, want := range tests {
+		if got := strings.Join(morph.frame(progress), "\n"); got != want {
+			t.Errorf("frame(%v) = %q, want %q", progress, got, want)
+		}
+	}
+}
+
+func TestMorphStartsOnRemoteReveal(t *testing.T) {
+	t.Setenv("NO_MOTION", "")
+	content := "```go\nx := 1\n```\n\n:morph:\n\n```go\nx := 2\n```\n"
+	slides := []slide{newSlide(content, content, slideMeta{}, "01.md", -1)}
+	m := newStaticModel(".", "", slides, deckConfig{}, 80, 24)
+	m.static = false
+
+	next, _ := m.Update(remoteKeyMsg{key: "j"})
+	started := next.(model).morph
+	if started == nil {
+		t.Fatal("revealing a morph step from the presenter console didn't start the morph")
+	}
+	next, _ = next.(model).update(morphTickMsg{id: started.id - 1})
+	if next.(model).morph == nil {
+		t.Error("a tick of a replaced morph ended the current one")
+	}
+}
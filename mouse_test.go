@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sends reports whether running cmd, and the commands it batches, sends want.
+func sends(cmd tea.Cmd, want tea.Msg) bool {
+	if cmd == nil {
+		return false
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if sends(c, want) {
+				return true
+			}
+		}
+		return false
+	}
+	return reflect.DeepEqual(msg, want)
+}
+
+func TestMouseCaptureIsOptIn(t *testing.T) {
+	slides := []slide{newSlide("# One\n", "# One\n", slideMeta{}, "01.md", -1)}
+	m := initialModel(".", "", nil, nil)
+	next, _ := m.update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	next, cmd := next.(model).update(slidesLoadedMsg{slides: slides})
+	if sends(cmd, tea.EnableMouseCellMotion()) {
+		t.Error("the mouse was captured without the deck asking")
+	}
+	next, cmd = next.(model).update(slidesLoadedMsg{slides: slides, config: deckConfig{Mouse: setting(true)}})
+	if !sends(cmd, tea.EnableMouseCellMotion()) {
+		t.Error("mouse: true didn't capture the mouse")
+	}
+	_, cmd = next.(model).update(slidesLoadedMsg{slides: slides})
+	if !sends(cmd, tea.DisableMouse()) {
+		t.Error("turning mouse off didn't release the mouse")
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Speaker notes are written either as an HTML comment starting with
+// "notes:" or as a fenced ```notes block. Neither is ever shown to the
+// audience.
+var (
+	notesCommentRe = regexp.MustCompile(`(?s)<!--\s*notes:\s*(.*?)\s*-->`)
+	notesBlockRe   = regexp.MustCompile("(?s)```notes\\s*\\n(.*?)\\n```")
+)
+
+// parseNotes collects the speaker notes of a slide, front matter notes
+// first, separated by blank lines.
+func parseNotes(content, frontMatter string) string {
+	var notes []string
+	if note := strings.TrimSpace(frontMatter); note != "" {
+		notes = append(notes, note)
+	}
+	for _, re := range []*regexp.Regexp{notesCommentRe, notesBlockRe} {
+		for _, match := range re.FindAllStringSubmatch(content, -1) {
+			if note := strings.TrimSpace(match[1]); note != "" {
+				notes = append(notes, note)
+			}
+		}
+	}
+	return strings.Join(notes, "\n\n")
+}
+
+// stripNotes removes speaker notes from slide markdown before rendering.
+func stripNotes(content string) string {
+	content = notesCommentRe.ReplaceAllString(content, "")
+	return notesBlockRe.ReplaceAllString(content, "")
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestParseNotes(t *testing.T) {
+	content := "# Slide\n<!-- notes: Say hello -->\nText\n```notes\nMention the demo\n```\n<!-- a plain comment -->\n"
+	want := "From front matter\n\nSay hello\n\nMention the demo"
+	if got := parseNotes(content, " From front matter\n"); got != want {
+		t.Errorf("parseNotes = %q, want %q", got, want)
+	}
+	if got := parseNotes("# Slide\n<!-- notes: -->\n", ""); got != "" {
+		t.Errorf("parseNotes of empty notes = %q", got)
+	}
+}
+
+func TestStripNotes(t *testing.T) {
+	content := "# Slide\n<!-- notes: Say hello -->\nText\n```notes\nMention the demo\n```\n<!-- a plain comment -->\n"
+	if got, want := stripNotes(content), "# Slide\n\nText\n\n<!-- a plain comment -->\n"; got != want {
+		t.Errorf("stripNotes = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Overview grid cell size. Cells are at least overviewCellWidth columns wide
+// and share out whatever width is left over.
+const (
+	overviewCellWidth  = 30
+	overviewCellHeight = 9 // including the border, title and section name
+)
+
+// slideTitle returns the title of s: its front matter title, else its first
+// heading, else its first line of text.
+func slideTitle(s slide) string {
+	if s.meta.Title != "" {
+		return s.meta.Title
+	}
+	first := ""
+	for _, line := range strings.Split(stripNotes(stripCommandBlocks(s.content)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == ":reveal:" {
+			continue
+		}
+		if match := bigHeadingRe.FindStringSubmatch(line); match != nil {
+			return strings.TrimSpace(match[2])
+		}
+		if strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	return first
+}
+
+// overviewColumns returns how many thumbnails fit side by side.
+func (m model) overviewColumns() int {
+	return max(m.width/overviewCellWidth, 1)
+}
+
+// renderThumbnails renders every slide, fully revealed, at the width of an
+// overview cell. Blank lines are dropped so more of each slide fits.
+func (m model) renderThumbnails() []string {
+	width := m.width/m.overviewColumns() - 4
+	r := newRenderer(m.config.themeName(), max(width, 10))
+	thumbnails := make([]string, len(m.slides))
+	for i, s := range m.slides {
+		rendered, err := renderSlideLayout(r, m.config.themeName(), slideMarkdown(s, s.reveal.totalItems()), s.meta.Layout, max(width, 10))
+		if err != nil {
+			rendered = err.Error()
+		}
+		var lines []string
+		for _, line := range strings.Split(rendered, "\n") {
+			if strings.TrimSpace(ansi.Strip(line)) != "" {
+				lines = append(lines, ansi.Truncate(line, width, "…"))
+			}
+		}
+		thumbnails[i] = strings.Join(lines, "\n")
+	}
+	return thumbnails
+}
+
+// openOverview shows the slide grid with the current slide selected.
+func (m *model) openOverview() {
+	m.showOverview = true
+	m.overviewCursor = m.currentSlide
+	m.thumbnails = m.renderThumbnails()
+}
+
+// updateOverview handles keys while the grid is shown.
+func (m model) updateOverview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	columns := m.overviewColumns()
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "tab", "esc":
+		m.showOverview = false
+	case "left", "h":
+		m.overviewCursor--
+	case "right", "l":
+		m.overviewCursor++
+	case "up", "k":
+		m.overviewCursor -= columns
+	case "down", "j":
+		m.overviewCursor += columns
+	case "home":
+		m.overviewCursor = 0
+	case "end":
+		m.overviewCursor = len(m.slides) - 1
+	case "enter":
+		m.showOverview = false
+		return m.goToSlide(m.overviewCursor)
+	}
+	m.overviewCursor = clampInt(m.overviewCursor, 0, max(len(m.slides)-1, 0))
+	return m, nil
+}
+
+// overviewView renders the grid of slide thumbnails, scrolled so the
+// selected one is visible.
+func (m model) overviewView() string {
+	columns := m.overviewColumns()
+	cellWidth := m.width / columns
+
+	var rows []string
+	for start := 0; start < len(m.slides); start += columns {
+		var cells []string
+		for i := start; i < min(start+columns, len(m.slides)); i++ {
+			s := m.slides[i]
+			labelWidth := cellWidth - 4
+			title := lipgloss.NewStyle().Bold(true).Render(ansi.Truncate(fmt.Sprintf("%d  %s", i+1, slideTitle(s)), labelWidth, "…"))
+			lines := []string{title}
+			if s.sectionName != "" {
+				lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8")).Render(ansi.Truncate(s.sectionName, labelWidth, "…")))
+			}
+			if i < len(m.thumbnails) {
+				lines = append(lines, strings.Split(m.thumbnails[i], "\n")...)
+			}
+			lines = lines[:min(len(lines), overviewCellHeight-2)]
+
+			border := lipgloss.Color("#4B5563")
+			if i == m.overviewCursor {
+				border = lipgloss.Color("#F59E0B")
+			}
+			cells = append(cells, lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(border).
+				Padding(0, 1).
+				Width(cellWidth-2).
+				Height(overviewCellHeight-2).
+				MaxHeight(overviewCellHeight).
+				Render(strings.Join(lines, "\n")))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+
+	// Scroll whole rows so the selected slide stays on screen
+	visibleRows := max((m.height-1)/overviewCellHeight, 1)
+	cursorRow := m.overviewCursor / columns
+	first := clampInt(cursorRow-visibleRows+1, 0, max(len(rows)-visibleRows, 0))
+	if cursorRow < first {
+		first = cursorRow
+	}
+	last := min(first+visibleRows, len(rows))
+
+	help := fmt.Sprintf("OVERVIEW  %d/%d - arrows move - enter go to slide - tab close", m.overviewCursor+1, len(m.slides))
+	colors := m.config.StatusBar.withDefaults()
+	header := lipgloss.NewStyle().
+		Background(lipgloss.Color(colors.Inner)).
+		Foreground(lipgloss.Color(colors.Foreground)).
+		Width(m.width).
+		Padding(0, 1).
+		Render(help)
+	return header + "\n" + strings.Join(rows[first:last], "\n")
+}
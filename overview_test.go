@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func numberedSlides(n int) []slide {
+	slides := make([]slide, n)
+	for i := range slides {
+		content := fmt.Sprintf("# Slide %d\n", i+1)
+		slides[i] = newSlide(content, content, slideMeta{}, fmt.Sprintf("%02d.md", i+1), -1)
+	}
+	return slides
+}
+
+// pressKeys sends each space-separated key of keys to m.
+func pressKeys(m model, keys string) model {
+	for _, key := range strings.Fields(keys) {
+		var msg tea.KeyMsg
+		switch key {
+		case "left":
+			msg = tea.KeyMsg{Type: tea.KeyLeft}
+		case "right":
+			msg = tea.KeyMsg{Type: tea.KeyRight}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "home":
+			msg = tea.KeyMsg{Type: tea.KeyHome}
+		case "end":
+			msg = tea.KeyMsg{Type: tea.KeyEnd}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		next, _ := m.update(msg)
+		m = next.(model)
+	}
+	return m
+}
+
+func TestOverviewCursor(t *testing.T) {
+	// Seven slides in three columns:
+	//   0 1 2
+	//   3 4 5
+	//   6
+	tests := []struct {
+		keys string
+		want int
+	}{
+		{"", 0},
+		{"left", 0},
+		{"up", 0},
+		{"l l", 2},
+		{"right right right", 3},
+		{"j", 3},
+		{"down j", 6},
+		{"j j j", 6},
+		{"l j j", 6},
+		{"end", 6},
+		{"end k", 3},
+		{"end l", 6},
+		{"end home", 0},
+		{"end h h", 4},
+	}
+	m := newStaticModel(".", "", numberedSlides(7), deckConfig{}, 90, 40)
+	for _, tt := range tests {
+		got := pressKeys(m, "tab "+tt.keys)
+		if !got.showOverview || got.overviewCursor != tt.want {
+			t.Errorf("%q: cursor at %d (overview shown %v), want %d", tt.keys, got.overviewCursor, got.showOverview, tt.want)
+		}
+	}
+}
+
+func TestOverviewOpensOnCurrentSlide(t *testing.T) {
+	m := newStaticModel(".", "", numberedSlides(7), deckConfig{}, 90, 40)
+	m = pressKeys(m, "l l l l tab")
+	if m.overviewCursor != 4 {
+		t.Fatalf("overview opened on %d, want 4", m.overviewCursor)
+	}
+	if got := pressKeys(m, "j esc"); got.showOverview || got.currentSlide != 4 {
+		t.Errorf("esc: overview shown %v, slide %d; want the overview closed on slide 4", got.showOverview, got.currentSlide)
+	}
+	if got := pressKeys(m, "k enter"); got.showOverview || got.currentSlide != 1 {
+		t.Errorf("enter: overview shown %v, slide %d; want slide 1", got.showOverview, got.currentSlide)
+	}
+}
+
+func TestOverviewClampsOnReload(t *testing.T) {
+	m := newStaticModel(".", "", numberedSlides(7), deckConfig{}, 90, 40)
+	m = pressKeys(m, "tab end")
+	next, _ := m.update(slidesLoadedMsg{slides: numberedSlides(3)})
+	m = next.(model)
+	if !m.showOverview || m.overviewCursor != 2 {
+		t.Errorf("after the deck shrank the cursor is at %d, want 2", m.overviewCursor)
+	}
+	if len(m.thumbnails) != 3 {
+		t.Errorf("%d thumbnails for 3 slides", len(m.thumbnails))
+	}
+}
+
+func TestOverviewScrollsToCursor(t *testing.T) {
+	// Room for two rows of thumbnails
+	m := newStaticModel(".", "", numberedSlides(12), deckConfig{}, 90, 2*overviewCellHeight+1)
+	for _, tt := range []struct {
+		keys          string
+		shown, hidden string
+	}{
+		{"", "│ 1  Slide 1 ", "│ 7  Slide 7 "},
+		{"end", "│ 10  Slide 10 ", "│ 1  Slide 1 "},
+		{"end home", "│ 1  Slide 1 ", "│ 10  Slide 10 "},
+	} {
+		view := ansi.Strip(pressKeys(m, "tab "+tt.keys).View())
+		if !strings.Contains(view, tt.shown) || strings.Contains(view, tt.hidden) {
+			t.Errorf("%q: want %q shown and %q hidden in\n%s", tt.keys, tt.shown, tt.hidden, view)
+		}
+	}
+}
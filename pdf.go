@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// PDF cell metrics, in points. Go Mono advances 0.6em per glyph.
+const (
+	pdfFontSize   = 10.0
+	pdfCellWidth  = pdfFontSize * 0.6
+	pdfCellHeight = 12.0
+	pdfBaseline   = 9.0 // from the top of a cell
+)
+
+// pdfFace is one of the Go Mono faces embedded in PDFs, so any text the
+// fonts cover comes out as it does in PNG exports.
+type pdfFace struct {
+	name  string
+	ttf   []byte
+	font  *sfnt.Font
+	flags int          // FontDescriptor flags: fixed pitch, nonsymbolic, italic
+	used  map[int]rune // glyphs drawn, and the rune each stands for
+}
+
+// pdfFaces returns the faces, indexed by bold<<1 | italic.
+func pdfFaces() ([4]*pdfFace, error) {
+	faces := [4]*pdfFace{
+		{name: "GoMono", ttf: gomono.TTF, flags: 1 | 32},
+		{name: "GoMono-Italic", ttf: gomonoitalic.TTF, flags: 1 | 32 | 64},
+		{name: "GoMono-Bold", ttf: gomonobold.TTF, flags: 1 | 32},
+		{name: "GoMono-BoldItalic", ttf: gomonobolditalic.TTF, flags: 1 | 32 | 64},
+	}
+	for _, face := range faces {
+		f, err := sfnt.Parse(face.ttf)
+		if err != nil {
+			return faces, err
+		}
+		face.font, face.used = f, make(map[int]rune)
+	}
+	return faces, nil
+}
+
+// glyph returns the glyph face draws r with, recording it as used. Runes
+// the font lacks get glyph 0, which readers draw as an empty box.
+func (face *pdfFace) glyph(buf *sfnt.Buffer, r rune) int {
+	index, err := face.font.GlyphIndex(buf, r)
+	if err != nil {
+		index = 0
+	}
+	face.used[int(index)] = r
+	return int(index)
+}
+
+func pdfColor(c color.RGBA) string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// pdfPage draws one screen as a PDF content stream.
+func pdfPage(s *screen, faces [4]*pdfFace) string {
+	var b strings.Builder
+	var buf sfnt.Buffer
+	pageHeight := float64(s.height) * pdfCellHeight
+	fmt.Fprintf(&b, "%s rg 0 0 %g %g re f\n", pdfColor(s.page), float64(s.width)*pdfCellWidth, pageHeight)
+
+	for y := 0; y < s.height; y++ {
+		top := pageHeight - float64(y)*pdfCellHeight
+		s.runs(y, func(x, n int, text []rune, offsets []int, style cellStyle) {
+			left := float64(x) * pdfCellWidth
+			if style.bg != s.page {
+				fmt.Fprintf(&b, "%s rg %g %g %g %g re f\n", pdfColor(style.bg), left, top-pdfCellHeight, float64(n)*pdfCellWidth, pdfCellHeight)
+			}
+
+			font := 0
+			if style.bold {
+				font |= 2
+			}
+			if style.italic {
+				font |= 1
+			}
+
+			var run strings.Builder
+			runStart, blank := 0, true
+			flush := func() {
+				if !blank {
+					fmt.Fprintf(&b, "BT /F%d %g Tf %s rg %g %g Td <%s> Tj ET\n",
+						font+1, pdfFontSize, pdfColor(style.fg), left+float64(runStart)*pdfCellWidth, top-pdfBaseline, run.String())
+				}
+				run.Reset()
+				blank = true
+			}
+			for i, r := range text {
+				if shape, ok := shapeFor(r); ok {
+					flush()
+					cx := left + float64(offsets[i])*pdfCellWidth
+					fmt.Fprintf(&b, "%s rg", pdfColor(blend(style.fg, style.bg, shape.alpha)))
+					for _, polygon := range shape.polygons {
+						for j, p := range polygon {
+							op := "l"
+							if j == 0 {
+								op = "m"
+							}
+							fmt.Fprintf(&b, " %g %g %s", cx+p[0]*pdfCellWidth, top-p[1]*pdfCellHeight, op)
+						}
+						b.WriteString(" h")
+					}
+					b.WriteString(" f\n")
+					continue
+				}
+				if run.Len() == 0 {
+					runStart = offsets[i]
+				}
+				fmt.Fprintf(&run, "%04X", faces[font].glyph(&buf, r))
+				blank = blank && r == ' '
+				// Keep columns aligned past wide runes
+				if i+1 < len(offsets) && offsets[i+1]-offsets[i] > 1 {
+					fmt.Fprintf(&run, "%04X", faces[font].glyph(&buf, ' '))
+				}
+			}
+			flush()
+
+			if style.underline && strings.TrimSpace(string(text)) != "" {
+				fmt.Fprintf(&b, "%s rg %g %g %g 0.6 re f\n", pdfColor(style.fg), left, top-pdfBaseline-1.5, float64(n)*pdfCellWidth)
+			}
+		})
+	}
+	return b.String()
+}
+
+// toUnicode returns a CMap mapping the glyphs face drew back to their
+// runes, so text can be searched and copied from the PDF.
+func (face *pdfFace) toUnicode() string {
+	var glyphs []int
+	for g := range face.used {
+		if g != 0 {
+			glyphs = append(glyphs, g)
+		}
+	}
+	sort.Ints(glyphs)
+
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for len(glyphs) > 0 {
+		// A bfchar block holds at most 100 entries
+		block := glyphs[:min(len(glyphs), 100)]
+		glyphs = glyphs[len(block):]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(block))
+		for _, g := range block {
+			fmt.Fprintf(&b, "<%04X> <", g)
+			for _, unit := range utf16Units(face.used[g]) {
+				fmt.Fprintf(&b, "%04X", unit)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.String()
+}
+
+// utf16Units encodes r as UTF-16.
+func utf16Units(r rune) []uint16 {
+	if r < 0x10000 {
+		return []uint16{uint16(r)}
+	}
+	r -= 0x10000
+	return []uint16{uint16(0xD800 + r>>10), uint16(0xDC00 + r&0x3FF)}
+}
+
+// deflate compresses a stream for /FlateDecode.
+func deflate(data []byte) []byte {
+	var out bytes.Buffer
+	w := zlib.NewWriter(&out)
+	w.Write(data)
+	w.Close()
+	return out.Bytes()
+}
+
+// writePDF writes screens as the pages of a PDF document.
+func writePDF(path string, screens []*screen) error {
+	faces, err := pdfFaces()
+	if err != nil {
+		return err
+	}
+	contents := make([]string, len(screens))
+	for i, s := range screens {
+		contents[i] = pdfPage(s, faces)
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	stream := func(dict string, data []byte) {
+		object(fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data))
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-2 are the catalog and page tree, then a page and its content
+	// stream for each screen, then five objects for each face
+	const firstPage = 3
+	firstFont := firstPage + 2*len(screens)
+	kids := make([]string, len(screens))
+	for i := range screens {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	var resources strings.Builder
+	for i := range faces {
+		fmt.Fprintf(&resources, " /F%d %d 0 R", i+1, firstFont+5*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(screens)))
+	for i, s := range screens {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources << /Font <<%s >> >> /Contents %d 0 R >>",
+			float64(s.width)*pdfCellWidth, float64(s.height)*pdfCellHeight, resources.String(), firstPage+2*i+1))
+		stream("/Filter /FlateDecode", deflate([]byte(contents[i])))
+	}
+
+	var buf sfnt.Buffer
+	for i, face := range faces {
+		n := firstFont + 5*i
+		// Font units are scaled to the 1000 units per em PDF glyph space
+		upem := face.font.UnitsPerEm()
+		scale := func(v fixed.Int26_6) int { return int(v) * 1000 / (int(upem) << 6) }
+		bounds, err := face.font.Bounds(&buf, fixed.I(int(upem)), font.HintingNone)
+		if err != nil {
+			return err
+		}
+		metrics, err := face.font.Metrics(&buf, fixed.I(int(upem)), font.HintingNone)
+		if err != nil {
+			return err
+		}
+		italicAngle := 0
+		if face.flags&64 != 0 {
+			italicAngle = -12
+		}
+
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			face.name, n+1, n+4))
+		object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW %d /CIDToGIDMap /Identity >>",
+			face.name, n+2, int(pdfCellWidth/pdfFontSize*1000)))
+		object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags %d /FontBBox [%d %d %d %d] /ItalicAngle %d /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+			face.name, face.flags, scale(bounds.Min.X), -scale(bounds.Max.Y), scale(bounds.Max.X), -scale(bounds.Min.Y),
+			italicAngle, scale(metrics.Ascent), -scale(metrics.Descent), scale(metrics.CapHeight), n+3))
+		stream(fmt.Sprintf("/Filter /FlateDecode /Length1 %d", len(face.ttf)), deflate(face.ttf))
+		stream("", []byte(face.toUnicode()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
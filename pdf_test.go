@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestWritePDFPages(t *testing.T) {
+	screens := []*screen{
+		parseScreen("\x1b[1mFirst\x1b[0m page", 20, 3, darkPage),
+		parseScreen("Second, ünïcödé ⣿", 20, 3, darkPage),
+		parseScreen("\x1b[3;4mThird", 20, 3, lightPage),
+	}
+	path := filepath.Join(t.TempDir(), "deck.pdf")
+	if err := writePDF(path, screens); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Error("not a complete PDF file")
+	}
+	if pages := len(regexp.MustCompile(`/Type /Page\b[^s]`).FindAll(data, -1)); pages != len(screens) {
+		t.Errorf("%d pages, want %d", pages, len(screens))
+	}
+	if !bytes.Contains(data, []byte("/Count 3")) {
+		t.Error("the page tree doesn't count 3 pages")
+	}
+	if fonts := bytes.Count(data, []byte("/FontFile2")); fonts != 4 {
+		t.Errorf("%d embedded fonts, want 4", fonts)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// presenterModel is the `slidetty presenter` console: the current slide,
+// a preview of the next one, speaker notes, the timer and the reveal step,
+// kept in sync with the audience window over its Unix socket.
+type presenterModel struct {
+	root     string
+	deckFile string
+	socket   string
+	client   *syncClient
+	watcher  *deckWatcher
+	slides   []slide
+	config   deckConfig
+	state    syncState
+	current  *glamour.TermRenderer // renders the current slide
+	preview  *glamour.TermRenderer // renders the next slide
+	width    int
+	height   int
+	err      error
+}
+
+// syncConnectedMsg reports a successful connection to the audience window.
+type syncConnectedMsg struct {
+	client *syncClient
+}
+
+// syncRetryMsg asks the presenter to try connecting again.
+type syncRetryMsg struct{}
+
+// presenterTickMsg refreshes the timer once a second.
+type presenterTickMsg struct{}
+
+func newPresenterModel(root, deckFile string, watcher *deckWatcher) presenterModel {
+	return presenterModel{
+		root:     root,
+		deckFile: deckFile,
+		socket:   syncSocketPath(root, deckFile),
+		watcher:  watcher,
+	}
+}
+
+func connectSync(path string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := dialSync(path)
+		if err != nil {
+			return syncRetryMsg{}
+		}
+		return syncConnectedMsg{client: client}
+	}
+}
+
+func doPresenterTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return presenterTickMsg{}
+	})
+}
+
+func (m presenterModel) Init() tea.Cmd {
+	return tea.Batch(loadSlides(m.root, m.deckFile), m.watcher.wait(), connectSync(m.socket), doPresenterTick())
+}
+
+// columnWidths splits the window between the current slide and the
+// preview/notes column.
+func (m presenterModel) columnWidths() (left, right int) {
+	left = m.width * 3 / 5
+	return left, m.width - left - 1
+}
+
+func (m *presenterModel) rebuildRenderers() {
+	if m.width == 0 {
+		return
+	}
+	left, right := m.columnWidths()
+	m.current = newRenderer(m.config.themeName(), left-4)
+	m.preview = newRenderer(m.config.themeName(), right-4)
+}
+
+// send forwards a key to the audience window, or applies it locally when no
+// audience window is connected.
+func (m presenterModel) send(key string) (presenterModel, tea.Cmd) {
+	if m.client != nil {
+		if err := m.client.send(key); err == nil {
+			return m, nil
+		}
+	}
+	switch key {
+	case "l":
+		if m.state.Slide < len(m.slides)-1 {
+			m.state.Slide++
+			m.state.Step = clampRevealProgress(1, m.slides[m.state.Slide].reveal.totalItems())
+		}
+	case "h":
+		if m.state.Slide > 0 {
+			m.state.Slide--
+			m.state.Step = clampRevealProgress(1, m.slides[m.state.Slide].reveal.totalItems())
+		}
+	case "j":
+		total := 0
+		if m.state.Slide < len(m.slides) {
+			total = m.slides[m.state.Slide].reveal.totalItems()
+		}
+		if m.state.Step < total {
+			m.state.Step++
+		} else {
+			return m.send("l")
+		}
+	case "k":
+		if m.state.Step > 1 {
+			m.state.Step--
+		} else if m.state.Slide > 0 {
+			m.state.Slide--
+			m.state.Step = m.slides[m.state.Slide].reveal.totalItems()
+		}
+	}
+	return m, nil
+}
+
+func (m presenterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.rebuildRenderers()
+		return m, nil
+
+	case slidesLoadedMsg:
+		m.slides = msg.slides
+		m.config = msg.config
+		if m.state.TimerDuration == 0 {
+			m.state.TimerDuration = msg.config.duration()
+		}
+		m.rebuildRenderers()
+		return m, nil
+
+	case deckChangedMsg:
+		return m, tea.Batch(loadSlides(m.root, m.deckFile), m.watcher.wait())
+
+	case syncConnectedMsg:
+		m.client = msg.client
+		return m, m.client.wait()
+
+	case syncRetryMsg:
+		return m, tea.Tick(time.Second, func(time.Time) tea.Msg {
+			return connectSync(m.socket)()
+		})
+
+	case syncLostMsg:
+		if m.client != nil {
+			m.client.Close()
+			m.client = nil
+		}
+		return m, connectSync(m.socket)
+
+	case syncStateMsg:
+		m.state = syncState(msg)
+		return m, m.client.wait()
+
+	case presenterTickMsg:
+		return m, doPresenterTick()
+
+	case errMsg:
+		m.err = msg
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			if m.client != nil {
+				m.client.Close()
+			}
+			return m, tea.Quit
+		case "right", "l":
+			return m.send("l")
+		case "left", "h":
+			return m.send("h")
+		case "down", "j":
+			return m.send("j")
+		case "up", "k":
+			return m.send("k")
+		case "w":
+			return m.send("w")
+		}
+	}
+	return m, nil
+}
+
+// fitHeight cuts or pads s to exactly height lines.
+func fitHeight(s string, height int) string {
+	if height <= 0 {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m presenterModel) renderSlide(r *glamour.TermRenderer, width, index, step int) string {
+	if r == nil || index < 0 || index >= len(m.slides) {
+		return ""
+	}
+	s := m.slides[index]
+	rendered, err := renderSlideLayout(r, m.config.themeName(), slideMarkdown(s, step), s.meta.Layout, width)
+	if err != nil {
+		return "Error rendering markdown: " + err.Error()
+	}
+	return rendered
+}
+
+func (m presenterModel) View() string {
+	if m.err != nil {
+		return fmt.Sprintf("Error: %v\n\nPress 'q' to quit.", m.err)
+	}
+	if len(m.slides) == 0 || m.width == 0 {
+		return "Loading slides...\n\nPress 'q' to quit."
+	}
+
+	index := clampInt(m.state.Slide, 0, len(m.slides)-1)
+	current := m.slides[index]
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#94A3B8"))
+
+	// Header: position, reveal step, timer and connection state
+	header := fmt.Sprintf("PRESENTER  Slide %d/%d", index+1, len(m.slides))
+	if current.sectionName != "" {
+		header += "  " + current.sectionName
+	}
+	if total := current.reveal.totalItems(); total > 0 {
+		header += fmt.Sprintf("  Step %d/%d", clampRevealProgress(m.state.Step, total), total)
+	}
+	if m.state.TimerDuration > 0 {
+		elapsed := m.state.elapsed()
+		remaining := m.state.TimerDuration - elapsed
+		if remaining < 0 {
+			remaining = 0
+		}
+		status := "Paused"
+		if m.state.TimerRunning {
+			status = "Running"
+		}
+		header += fmt.Sprintf("  Timer: %dm | %dm - %s", int(elapsed.Minutes()), int(remaining.Minutes()), status)
+	}
+	if budget := current.timeBudget(); budget > 0 {
+		header += fmt.Sprintf("  Budget: %s", budget)
+	}
+	if m.client == nil {
+		header += "  (audience window not connected)"
+	}
+	headerBar := lipgloss.NewStyle().
+		Background(lipgloss.Color("#1E3A8A")).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Width(m.width).
+		Padding(0, 1).
+		Render(header)
+
+	bodyHeight := m.height - 1
+	left, right := m.columnWidths()
+
+	// Left: the slide as the audience currently sees it
+	now := labelStyle.Render("NOW") + "\n" + m.renderSlide(m.current, left-4, index, m.state.Step)
+	leftColumn := lipgloss.NewStyle().Width(left).Render(fitHeight(now, bodyHeight))
+
+	// Right: next slide preview above the speaker notes
+	previewHeight := bodyHeight / 2
+	next := labelStyle.Render("NEXT") + "\n"
+	if index+1 < len(m.slides) {
+		next += m.renderSlide(m.preview, right-4, index+1, 1)
+	} else {
+		next += "\n  End of deck"
+	}
+	notes := current.notes
+	if notes == "" {
+		notes = "No notes for this slide."
+	}
+	notesBlock := labelStyle.Render("NOTES") + "\n" + lipgloss.NewStyle().Width(right-2).PaddingLeft(1).Render(notes)
+	rightColumn := lipgloss.NewStyle().
+		Width(right).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(lipgloss.Color("#475569")).
+		Render(fitHeight(next, previewHeight) + "\n" + fitHeight(notesBlock, bodyHeight-previewHeight))
+
+	return headerBar + "\n" + lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, rightColumn)
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// killProcessGroup kills p. Windows has no process groups to signal, so
+// processes p started are left to the closing console.
+func killProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
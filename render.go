@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// runRender implements `slidetty render [flags] [deck]`: it prints the
+// frames View produces at a fixed size, without a terminal, so decks can be
+// snapshot-tested.
+func runRender(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: slidetty render [flags] [deck]")
+		fs.PrintDefaults()
+	}
+	width := fs.Int("width", 100, "terminal width in columns")
+	height := fs.Int("height", 30, "terminal height in rows")
+	slideNum := fs.Int("slide", 1, "slide to render, counting from 1")
+	step := fs.Int("step", 0, "reveal step to render (default: fully revealed)")
+	all := fs.Bool("all", false, "render every slide in sequence")
+	steps := fs.Bool("steps", false, "with --all, render every reveal step of every slide")
+	stripANSI := fs.Bool("strip-ansi", false, "remove colors and other escape sequences")
+	positional := parseFlags(fs, args)
+	if len(positional) > 1 {
+		return fmt.Errorf("unexpected argument %q; render takes a single deck", positional[1])
+	}
+
+	if *width <= 0 || *height <= 0 {
+		return fmt.Errorf("--width and --height must be positive")
+	}
+
+	var deck string
+	if len(positional) > 0 {
+		deck = positional[0]
+	}
+	root, deckFile, err := resolveDeck(deck)
+	if err != nil {
+		return err
+	}
+	slides, cfg, err := readDeck(root, deckFile)
+	if err != nil {
+		return err
+	}
+	if len(slides) == 0 {
+		return fmt.Errorf("no slides found in %s", root)
+	}
+	m := newStaticModel(root, deckFile, slides, cfg, *width, *height)
+
+	var frames []terminalFrame
+	if *all {
+		frames = terminalFrames(m, *steps)
+	} else {
+		if *slideNum < 1 || *slideNum > len(slides) {
+			return fmt.Errorf("--slide %d out of range; the deck has %d slides", *slideNum, len(slides))
+		}
+		index := *slideNum - 1
+		available := revealSteps(slides[index])
+		k := available[len(available)-1]
+		// A slide without reveals has the single step 0, which is also its first
+		if *step != 0 && !(*step == 1 && k == 0) {
+			if *step < available[0] || *step > k {
+				return fmt.Errorf("--step %d out of range; slide %d has %d reveal steps", *step, *slideNum, k)
+			}
+			k = *step
+		}
+		frames = []terminalFrame{{output: m.frame(index, k)}}
+	}
+
+	for i, f := range frames {
+		output := f.output
+		if *stripANSI {
+			output = ansi.Strip(output)
+		}
+		// Frames are separated by a header naming the slide and step, so a
+		// golden file diff points at the frame that changed
+		if len(frames) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "=== %s ===\n", f.name)
+		}
+		fmt.Fprintln(out, output)
+	}
+	return nil
+}
+
+// parseFlags parses args with fs wherever the flags appear, before or after
+// the positional arguments, and returns the positional arguments. Arguments
+// after "--" are all positional.
+func parseFlags(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...)
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+const goldenDeck = "testdata/render/deck"
+
+// TestRenderGolden renders the deck in testdata/render/deck and compares
+// the frames with the golden files next to it. Run with -update after an
+// intended change to the rendering.
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"all-steps", []string{"--strip-ansi", "--all", "--steps", "--width", "60", "--height", "16", goldenDeck}},
+		{"narrow", []string{"--strip-ansi", "--slide", "2", "--width", "30", "--height", "12", goldenDeck}},
+		{"no-reveal-step-1", []string{"--strip-ansi", "--slide", "5", "--step", "1", "--width", "60", "--height", "16", goldenDeck}},
+		{"flags-after-deck", []string{"--slide", "4", goldenDeck, "--step", "2", "--strip-ansi", "--width", "60", "--height", "16"}},
+		// Colors are kept where they are what changes between steps
+		{"highlight-colors", []string{"--slide", "3", "--step", "1", "--width", "40", "--height", "10", goldenDeck}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := runRender(tt.args, &out); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "render", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != string(want) {
+				t.Errorf("output differs from %s; run go test -update if the change is intended\n--- got\n%s\n--- want\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{goldenDeck, "extra"}, `unexpected argument "extra"`},
+		{[]string{"--slide", "9", goldenDeck}, "out of range"},
+		{[]string{"--slide", "1", "--step", "4", goldenDeck}, "out of range"},
+	}
+	for _, tt := range tests {
+		err := runRender(tt.args, &bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("runRender(%q) = %v, want an error containing %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	width := fs.Int("width", 0, "")
+	all := fs.Bool("all", false, "")
+	positional := parseFlags(fs, []string{"deck", "--width", "40", "--all", "--", "--not-a-flag"})
+	if *width != 40 || !*all {
+		t.Errorf("width, all = %d, %v; want 40, true", *width, *all)
+	}
+	if want := []string{"deck", "--not-a-flag"}; strings.Join(positional, " ") != strings.Join(want, " ") {
+		t.Errorf("positional = %q, want %q", positional, want)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// revealDim is the reveal setting that shows fragments not yet revealed
+// dimmed instead of leaving them out.
+const revealDim = "dim"
+
+// revealDirectiveRe matches a reveal directive: ":reveal:", or ":reveal 3:"
+// to place the fragments after it at that point in the reveal order.
+var revealDirectiveRe = regexp.MustCompile(`^:reveal(?:\s+(\d+))?:$`)
+
+// Markers around the text of fragments shown dimmed. They are private use
+// characters, which glamour passes through untouched, and never reach the
+// screen.
+const (
+	dimStart = "\uE000"
+	dimEnd   = "\uE001"
+)
+
+var hiddenStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#4B5563"))
+
+// revealPrefixRe matches the markdown syntax a line starts with, which has
+// to stay ahead of the dim markers for the line to keep its meaning.
+var revealPrefixRe = regexp.MustCompile(`^\s*(?:(?:[-*+]|\d+\.)\s+|#{1,6}\s+|>\s*)*`)
+
+// revealFragment is what one step of a reveal directive shows: the lines of
+// a list item, paragraph, code block, heading, table row or column. Steps
+// through a code block's highlighted lines are fragments too.
+type revealFragment struct {
+	order     int
+	lines     []int
+	immediate bool // shown at the first step: list items and code highlights
+	highlight *codeHighlight
+	morph     *codeMorph
+}
+
+// revealFragments returns the fragments the block starting at lines[i]
+// reveals and the index of the line after them. Lists and tables reveal an
+// item or row at a time, a ":::columns" row a column at a time, and any
+// other block all at once. A list reveals items as deeply indented as its
+// first or deeper, so a directive inside a list item reveals the list
+// nested in it.
+func revealFragments(lines []string, i int) ([]revealFragment, int) {
+	if i >= len(lines) {
+		return nil, i
+	}
+	trimmed := strings.TrimSpace(lines[i])
+	var fragments []revealFragment
+	switch {
+	case isListItem(lines[i]):
+		indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " \t"))
+		for i < len(lines) {
+			if strings.TrimSpace(lines[i]) == "" {
+				i++
+				continue
+			}
+			if !isListItem(lines[i]) || len(lines[i])-len(strings.TrimLeft(lines[i], " \t")) < indent {
+				break
+			}
+			item := []int{i}
+			i++
+			for i < len(lines) {
+				line := lines[i]
+				if strings.TrimSpace(line) == "" {
+					item = append(item, i)
+					i++
+					break
+				}
+				if isListItem(line) {
+					break
+				}
+				if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+					item = append(item, i)
+					i++
+					continue
+				}
+				break
+			}
+			fragments = append(fragments, revealFragment{lines: item, immediate: true})
+		}
+		return fragments, i
+
+	case isFenceLine(lines[i]):
+		fence := openFence(lines[i])
+		block := []int{i}
+		for i++; i < len(lines); i++ {
+			block = append(block, i)
+			if fence.closes(lines[i]) {
+				i++
+				break
+			}
+		}
+		return []revealFragment{{lines: block}}, i
+
+	case strings.HasPrefix(trimmed, ":::columns"):
+		var fence codeFence
+		for i++; i < len(lines); i++ {
+			fence.step(lines[i])
+			if !fence.inside() {
+				if _, ok := isDirective(lines[i], ""); ok {
+					return fragments, i + 1
+				}
+				if _, ok := isDirective(lines[i], "column"); ok {
+					fragments = append(fragments, revealFragment{})
+					continue
+				}
+			}
+			if len(fragments) > 0 {
+				column := &fragments[len(fragments)-1]
+				column.lines = append(column.lines, i)
+			}
+		}
+		return fragments, i
+
+	case strings.HasPrefix(trimmed, "|"):
+		// The header and the line under it stay; the rows below reveal
+		for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+			fragments = append(fragments, revealFragment{lines: []int{i}})
+		}
+		return fragments, i
+
+	case strings.HasPrefix(trimmed, "#"):
+		return []revealFragment{{lines: []int{i}}}, i + 1
+	}
+
+	// A paragraph, quote or image runs to the next blank line
+	var block []int
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		if len(block) > 0 && revealDirectiveRe.MatchString(strings.TrimSpace(lines[i])) {
+			break
+		}
+		block = append(block, i)
+	}
+	return []revealFragment{{lines: block}}, i
+}
+
+// dimsHidden reports whether the slide shows fragments it hasn't revealed
+// yet dimmed, by its own reveal setting or else the deck's.
+func (s slide) dimsHidden() bool {
+	if s.meta.Reveal != "" {
+		return s.meta.Reveal == revealDim
+	}
+	return s.deckReveal == revealDim
+}
+
+// dimLine marks the text of a line of a hidden fragment, after its list
+// marker, heading hashes or quote marker, for dimHidden to find once
+// rendered. Fences and column directives are left alone.
+func dimLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || isFenceLine(line) || strings.HasPrefix(trimmed, ":::") {
+		return line
+	}
+	if strings.HasPrefix(trimmed, "|") {
+		// Marking the end of the last cell keeps the other cells aligned
+		cell := strings.TrimRight(strings.TrimRight(line, " \t"), "|")
+		return cell + dimStart + dimEnd + line[len(cell):]
+	}
+	prefix := revealPrefixRe.FindString(line)
+	return prefix + dimStart + line[len(prefix):] + dimEnd
+}
+
+// dimHidden greys out the rendered lines between dim markers, and removes
+// the markers.
+func dimHidden(rendered string) string {
+	if !strings.Contains(rendered, dimStart) {
+		return rendered
+	}
+	unmark := strings.NewReplacer(dimStart, "", dimEnd, "")
+	lines := strings.Split(rendered, "\n")
+	dimmed := false
+	for i, line := range lines {
+		start, end := strings.LastIndex(line, dimStart), strings.LastIndex(line, dimEnd)
+		if dimmed || start >= 0 {
+			lines[i] = hiddenStyle.Render(unmark.Replace(ansi.Strip(line)))
+		} else {
+			lines[i] = unmark.Replace(line)
+		}
+		if start >= 0 || end >= 0 {
+			dimmed = start > end
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRevealFragments(t *testing.T) {
+	tests := []struct {
+		name      string
+		markdown  string
+		want      [][]int // lines of each fragment
+		immediate bool
+		next      int
+	}{
+		{"list", "- a\n- b\n  more b\n- c\nafter", [][]int{{0}, {1, 2}, {3}}, true, 4},
+		{"nested list stops at outer item", "  - a\n  - b\n- outer", [][]int{{0}, {1}}, true, 2},
+		{"fence", "```go\nx\n```\nafter", [][]int{{0, 1, 2}}, false, 3},
+		{"table rows", "| a |\n|---|\n| 1 |\n| 2 |\n\nafter", [][]int{{2}, {3}}, false, 4},
+		{"heading", "## Title\ntext", [][]int{{0}}, false, 1},
+		{"paragraph", "one\ntwo\n\nthree", [][]int{{0, 1}}, false, 2},
+		{"paragraph ends at directive", "one\n:reveal:\ntwo", [][]int{{0}}, false, 1},
+		{"columns", ":::columns\n:::column\nleft\n:::column\nright\n:::\nafter", [][]int{{2}, {4}}, false, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragments, next := revealFragments(strings.Split(tt.markdown, "\n"), 0)
+			var got [][]int
+			for _, f := range fragments {
+				got = append(got, f.lines)
+				if f.immediate != tt.immediate {
+					t.Errorf("fragment %v immediate = %v, want %v", f.lines, f.immediate, tt.immediate)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) || next != tt.next {
+				t.Errorf("revealFragments = %v, %d; want %v, %d", got, next, tt.want, tt.next)
+			}
+		})
+	}
+}
+
+func TestDimLine(t *testing.T) {
+	tests := map[string]string{
+		"- item":    "- " + dimStart + "item" + dimEnd,
+		"## Title":  "## " + dimStart + "Title" + dimEnd,
+		"| a | b |": "| a | b " + dimStart + dimEnd + "|",
+		"```go":     "```go",
+		"":          "",
+	}
+	for line, want := range tests {
+		if got := dimLine(line); got != want {
+			t.Errorf("dimLine(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/creack/pty"
+)
+
+// maxScrollback caps how many lines of command output are kept.
+const maxScrollback = 5000
+
+// commandOutputMsg carries a chunk of output from run id.
+type commandOutputMsg struct {
+	id   int
+	data []byte
+}
+
+// commandExitedMsg reports that run id finished.
+type commandExitedMsg struct {
+	id  int
+	err error
+}
+
+// commandRun is a command block executing in its own pseudo-terminal.
+type commandRun struct {
+	id     int
+	cmd    *exec.Cmd
+	pty    *os.File
+	events chan tea.Msg
+	done   chan struct{} // closed once nothing reads events any more
+	killed bool
+}
+
+// shell returns the shell command blocks run in.
+func (c runConfig) shell() string {
+	if c.Shell != "" {
+		return c.Shell
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// dir returns the working directory for commands of the deck at root.
+func (c runConfig) dir(root string) string {
+	if c.Dir == "" {
+		return root
+	}
+	if filepath.IsAbs(c.Dir) {
+		return c.Dir
+	}
+	return filepath.Join(root, c.Dir)
+}
+
+// allows reports whether command may run without asking first.
+func (c runConfig) allows(command string) bool {
+	for _, pattern := range c.Allow {
+		re := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if ok, _ := regexp.MatchString(re, command); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// startCommand runs command through the configured shell in a pseudo-terminal
+// of the given size, streaming its output until it exits.
+func startCommand(id int, command, root string, cfg runConfig, cols, rows int) (*commandRun, error) {
+	cmd := exec.Command(cfg.shell(), "-c", command)
+	cmd.Dir = cfg.dir(root)
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &commandRun{id: id, cmd: cmd, pty: f, events: make(chan tea.Msg, 64), done: make(chan struct{})}
+	go func() {
+		// Once the panel is closed, output is dropped, but the terminal is
+		// still read to the end so the command can be waited for
+		send := func(msg tea.Msg) {
+			select {
+			case r.events <- msg:
+			case <-r.done:
+			}
+		}
+		buf := make([]byte, 4096)
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				send(commandOutputMsg{id: id, data: append([]byte(nil), buf[:n]...)})
+			}
+			if err != nil {
+				break
+			}
+		}
+		send(commandExitedMsg{id: id, err: cmd.Wait()})
+		f.Close()
+		close(r.events)
+	}()
+	return r, nil
+}
+
+// wait returns a command that blocks until the run produces output or
+// exits. It must be re-issued after each message.
+func (r *commandRun) wait() tea.Cmd {
+	if r == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg, ok := <-r.events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+func (r *commandRun) resize(cols, rows int) {
+	if r == nil {
+		return
+	}
+	pty.Setsize(r.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// kill stops the command and every process it started. Closing the
+// terminal also hangs up anything that left the process group.
+func (r *commandRun) kill() {
+	if r == nil || r.cmd.Process == nil {
+		return
+	}
+	r.killed = true
+	killProcessGroup(r.cmd.Process)
+	r.pty.Close()
+}
+
+// close stops delivering the run's output, for when the panel showing it
+// goes away.
+func (r *commandRun) close() {
+	if r == nil {
+		return
+	}
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+	}
+}
+
+// outputPanel shows the output of the last command run from the slide, on
+// top of the slide, with scrollback.
+type outputPanel struct {
+	command string
+	run     *commandRun
+	running bool
+	status  string
+	lines   []string
+	partial string // the line being written, not yet ended by a newline
+	pending []byte // an escape sequence or rune cut off at the end of a chunk
+	scroll  int    // lines scrolled up from the bottom; 0 follows new output
+}
+
+// write appends output, keeping color sequences and dropping the cursor
+// movement and other escapes the panel cannot honor.
+func (p *outputPanel) write(data []byte) {
+	data = append(p.pending, data...)
+	p.pending = nil
+
+	var line strings.Builder
+	line.WriteString(p.partial)
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == 0x1b:
+			end, complete := escapeEnd(data, i)
+			if !complete {
+				p.pending = append([]byte(nil), data[i:]...)
+				i = len(data)
+				continue
+			}
+			if data[i+1] == '[' && data[end-1] == 'm' {
+				line.Write(data[i:end])
+			}
+			i = end
+		case c == '\n':
+			p.lines = append(p.lines, line.String())
+			line.Reset()
+			i++
+		case c == '\r':
+			// A bare carriage return starts the line over, as progress
+			// bars expect
+			j := i
+			for j < len(data) && data[j] == '\r' {
+				j++
+			}
+			if j == len(data) {
+				p.pending = append([]byte(nil), data[i:]...)
+			} else if data[j] != '\n' {
+				line.Reset()
+			}
+			i = j
+		case c == '\t':
+			line.WriteString("    ")
+			i++
+		case c < 0x20 || c == 0x7f:
+			i++
+		default:
+			if !utf8.FullRune(data[i:]) {
+				p.pending = append([]byte(nil), data[i:]...)
+				i = len(data)
+				continue
+			}
+			_, size := utf8.DecodeRune(data[i:])
+			line.Write(data[i : i+size])
+			i += size
+		}
+	}
+	p.partial = line.String()
+	if len(p.lines) > maxScrollback {
+		p.lines = p.lines[len(p.lines)-maxScrollback:]
+	}
+}
+
+// escapeEnd returns the index just past the escape sequence at data[i], and
+// whether the sequence is complete.
+func escapeEnd(data []byte, i int) (int, bool) {
+	if i+1 >= len(data) {
+		return len(data), false
+	}
+	switch data[i+1] {
+	case '[':
+		for end := i + 2; end < len(data); end++ {
+			if data[end] >= 0x40 && data[end] <= 0x7e {
+				return end + 1, true
+			}
+		}
+		return len(data), false
+	case ']':
+		for end := i + 2; end < len(data); end++ {
+			if data[end] == 0x07 {
+				return end + 1, true
+			}
+			if data[end] == 0x1b && end+1 < len(data) && data[end+1] == '\\' {
+				return end + 2, true
+			}
+		}
+		return len(data), false
+	}
+	return i + 2, true
+}
+
+// allLines returns the output lines including the unfinished last one.
+func (p *outputPanel) allLines() []string {
+	if p.partial == "" {
+		return p.lines
+	}
+	return append(p.lines[:len(p.lines):len(p.lines)], p.partial)
+}
+
+func (p *outputPanel) scrollBy(delta, visible int) {
+	maxScroll := len(p.allLines()) - visible
+	p.scroll = clampInt(p.scroll+delta, 0, max(maxScroll, 0))
+}
+
+// exited records how the command ended.
+func (p *outputPanel) exited(err error) {
+	p.running = false
+	var exitErr *exec.ExitError
+	switch {
+	case p.run != nil && p.run.killed:
+		p.status = "killed"
+	case err == nil:
+		p.status = "exit 0"
+	case errors.As(err, &exitErr):
+		p.status = fmt.Sprintf("exit %d", exitErr.ExitCode())
+	default:
+		p.status = err.Error()
+	}
+}
+
+// outputPanelSize returns the inner size of the output panel, which covers
+// the lower two thirds of the slide area.
+func (m model) outputPanelSize() (cols, rows int) {
+	return max(m.width-4, 10), max((m.height-2)*2/3-2, 3)
+}
+
+// view renders the panel as a bordered box of the given outer size.
+func (p *outputPanel) view(width, height int) string {
+	innerWidth, innerHeight := max(width-4, 1), max(height-2, 1)
+
+	lines := p.allLines()
+	end := len(lines) - p.scroll
+	start := max(end-innerHeight, 0)
+	visible := make([]string, 0, innerHeight)
+	for _, line := range lines[start:end] {
+		visible = append(visible, ansi.Truncate(line, innerWidth, "…")+"\x1b[0m")
+	}
+	for len(visible) < innerHeight {
+		visible = append(visible, "")
+	}
+
+	status := p.status
+	help := "x kill · ↑/↓ scroll · esc close"
+	if !p.running {
+		help = "↑/↓ scroll · esc close"
+	}
+	if p.scroll > 0 {
+		status += fmt.Sprintf(" · %d lines below", p.scroll)
+	}
+	title := ansi.Truncate(fmt.Sprintf(" $ %s ", p.command), max(innerWidth-lipgloss.Width(status)-4, 8), "…")
+
+	borderColor := lipgloss.Color("#1A602C")
+	if !p.running {
+		borderColor = lipgloss.Color("#4B5563")
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1).
+		Width(width - 2).
+		Height(innerHeight).
+		Render(strings.Join(visible, "\n"))
+
+	// Put the command and its status into the top border, the keys into
+	// the bottom one
+	boxLines := strings.Split(box, "\n")
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Bold(true)
+	border := lipgloss.NewStyle().Foreground(borderColor)
+	top := label.Render(title) + border.Render(strings.Repeat("─", max(width-2-lipgloss.Width(title)-lipgloss.Width(status)-2, 0))) + label.Render(" "+status+" ")
+	boxLines[0] = border.Render("╭") + ansi.Truncate(top, width-2, "") + border.Render("╮")
+	bottom := border.Render(strings.Repeat("─", max(width-2-lipgloss.Width(help)-2, 0))) + lipgloss.NewStyle().Foreground(lipgloss.Color("#94A3B8")).Render(" "+help+" ")
+	boxLines[len(boxLines)-1] = border.Render("╰") + ansi.Truncate(bottom, width-2, "") + border.Render("╯")
+	return strings.Join(boxLines, "\n")
+}
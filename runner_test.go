@@ -0,0 +1,77 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// drain reads what is left of a run's events, failing if the run doesn't
+// finish in time.
+func drain(t *testing.T, r *commandRun) string {
+	t.Helper()
+	var output strings.Builder
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg, ok := <-r.events:
+			if !ok {
+				return output.String()
+			}
+			if out, isOutput := msg.(commandOutputMsg); isOutput {
+				output.Write(out.data)
+			}
+		case <-timeout:
+			t.Fatal("the command's reader never finished")
+		}
+	}
+}
+
+func TestCommandRunKillsChildren(t *testing.T) {
+	r, err := startCommand(1, "sleep 30 & echo $!; wait", t.TempDir(), runConfig{Shell: "/bin/sh"}, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := (<-r.events).(commandOutputMsg)
+	child, err := strconv.Atoi(strings.TrimSpace(string(msg.data)))
+	if err != nil {
+		t.Fatalf("reading the child's pid from %q: %v", msg.data, err)
+	}
+	r.kill()
+	drain(t, r)
+	// The child is gone once signalling it fails
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(child, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("child %d outlived the killed command", child)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCommandRunCloseStopsDelivery(t *testing.T) {
+	r, err := startCommand(1, "for i in $(seq 1 2000); do echo line $i; done", t.TempDir(), runConfig{Shell: "/bin/sh"}, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing reads the events once the panel closes; the reader must still
+	// get to the end and wait for the command
+	r.close()
+	r.close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat("/proc/" + strconv.Itoa(r.cmd.Process.Pid)); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the command was never waited for")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	drain(t, r)
+}
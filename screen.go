@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/mattn/go-runewidth"
+)
+
+// screen is a grid of terminal cells reconstructed from ANSI output, used
+// to export what the audience saw as images and PDF pages.
+type screen struct {
+	width  int
+	height int
+	cells  [][]cell
+	page   color.RGBA // background of cells the output leaves uncolored
+}
+
+// cell is one terminal column. The second column of a wide rune has r == 0.
+type cell struct {
+	r     rune
+	style cellStyle
+}
+
+type cellStyle struct {
+	fg        color.RGBA
+	bg        color.RGBA
+	bold      bool
+	italic    bool
+	underline bool
+}
+
+// pageColors are the colors of text and background the output doesn't
+// color itself, as a terminal with the deck's theme would show them.
+type pageColors struct {
+	fg color.RGBA
+	bg color.RGBA
+}
+
+var (
+	darkPage  = pageColors{fg: color.RGBA{0xE5, 0xE7, 0xEB, 0xFF}, bg: color.RGBA{0x12, 0x12, 0x12, 0xFF}}
+	lightPage = pageColors{fg: color.RGBA{0x1C, 0x1C, 0x1C, 0xFF}, bg: color.RGBA{0xFA, 0xFA, 0xFA, 0xFF}}
+)
+
+// themePage returns the page colors for a glamour style, looked up like
+// glamour.WithStylePath does: a standard style name, else a JSON file. The
+// style's document colors are used where it sets them; otherwise the page
+// is light when the text is dark.
+func themePage(theme string) pageColors {
+	config, ok := styles.DefaultStyles[theme]
+	if !ok {
+		data, err := os.ReadFile(theme)
+		if err != nil || json.Unmarshal(data, &config) != nil || config == nil {
+			return darkPage
+		}
+	}
+	page := darkPage
+	document := config.Document.StylePrimitive
+	if fg, ok := styleColor(document.Color); ok {
+		if luminance(fg) < 0.5 {
+			page = lightPage
+		}
+		page.fg = fg
+	}
+	if bg, ok := styleColor(document.BackgroundColor); ok {
+		page.bg = bg
+	}
+	return page
+}
+
+// styleColor reads a glamour style color: an ANSI color number or a hex
+// color.
+func styleColor(value *string) (color.RGBA, bool) {
+	if value == nil {
+		return color.RGBA{}, false
+	}
+	if n, err := strconv.Atoi(*value); err == nil {
+		return xterm256(clampInt(n, 0, 255)), true
+	}
+	c, err := colorful.Hex(*value)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	r, g, b := c.RGB255()
+	return color.RGBA{r, g, b, 0xFF}, true
+}
+
+// luminance returns how light c looks, from 0 to 1.
+func luminance(c color.RGBA) float64 {
+	return (0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)) / 255
+}
+
+// ansiPalette holds the 16 basic terminal colors (xterm defaults).
+var ansiPalette = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xFF}, {0xCD, 0x00, 0x00, 0xFF}, {0x00, 0xCD, 0x00, 0xFF}, {0xCD, 0xCD, 0x00, 0xFF},
+	{0x00, 0x00, 0xEE, 0xFF}, {0xCD, 0x00, 0xCD, 0xFF}, {0x00, 0xCD, 0xCD, 0xFF}, {0xE5, 0xE5, 0xE5, 0xFF},
+	{0x7F, 0x7F, 0x7F, 0xFF}, {0xFF, 0x00, 0x00, 0xFF}, {0x00, 0xFF, 0x00, 0xFF}, {0xFF, 0xFF, 0x00, 0xFF},
+	{0x5C, 0x5C, 0xFF, 0xFF}, {0xFF, 0x00, 0xFF, 0xFF}, {0x00, 0xFF, 0xFF, 0xFF}, {0xFF, 0xFF, 0xFF, 0xFF},
+}
+
+// xterm256 returns color n of the xterm 256-color palette.
+func xterm256(n int) color.RGBA {
+	switch {
+	case n < 16:
+		return ansiPalette[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return color.RGBA{level(n / 36), level(n / 6 % 6), level(n % 6), 0xFF}
+	default:
+		gray := uint8(8 + (n-232)*10)
+		return color.RGBA{gray, gray, gray, 0xFF}
+	}
+}
+
+// parseScreen lays ANSI-styled text out on a width×height grid of the given
+// page. SGR sequences and cursor moves are interpreted; other escape
+// sequences are skipped.
+func parseScreen(output string, width, height int, page pageColors) *screen {
+	base := cellStyle{fg: page.fg, bg: page.bg}
+	s := &screen{width: width, height: height, cells: make([][]cell, height), page: page.bg}
+	for y := range s.cells {
+		s.cells[y] = make([]cell, width)
+		for x := range s.cells[y] {
+			s.cells[y][x] = cell{r: ' ', style: base}
+		}
+	}
+
+	style := base
+	x, y := 0, 0
+	for i := 0; i < len(output); {
+		if output[i] == 0x1b {
+			i = skipEscape(output, i, &style, base, &x, &y)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(output[i:])
+		i += size
+		switch r {
+		case '\n':
+			x, y = 0, y+1
+			continue
+		case '\r':
+			x = 0
+			continue
+		}
+		w := runewidth.RuneWidth(r)
+		if w == 0 || y >= height {
+			continue
+		}
+		if x+w <= width {
+			s.cells[y][x] = cell{r: r, style: style}
+			if w == 2 {
+				s.cells[y][x+1] = cell{r: 0, style: style}
+			}
+		}
+		x += w
+	}
+	return s
+}
+
+// skipEscape consumes the escape sequence starting at output[i], applying
+// it to style if it is SGR and to the cursor at x, y if it moves it, and
+// returns the index just past it.
+func skipEscape(output string, i int, style *cellStyle, base cellStyle, x, y *int) int {
+	if i+1 >= len(output) {
+		return i + 1
+	}
+	switch output[i+1] {
+	case '[':
+		end := i + 2
+		for end < len(output) && (output[end] < 0x40 || output[end] > 0x7e) {
+			end++
+		}
+		if end < len(output) {
+			switch output[end] {
+			case 'm':
+				applySGR(output[i+2:end], style, base)
+			default:
+				moveCursor(output[end], output[i+2:end], x, y)
+			}
+		}
+		return end + 1
+	case ']':
+		// OSC, terminated by BEL or ST
+		end := i + 2
+		for end < len(output) {
+			if output[end] == 0x07 {
+				return end + 1
+			}
+			if output[end] == 0x1b && end+1 < len(output) && output[end+1] == '\\' {
+				return end + 2
+			}
+			end++
+		}
+		return end
+	default:
+		return i + 2
+	}
+}
+
+// moveCursor applies the CSI sequence ending in final to the cursor at x,
+// y, if it is one that moves it.
+func moveCursor(final byte, params string, x, y *int) {
+	args := strings.Split(params, ";")
+	arg := func(i int) int {
+		if i < len(args) {
+			if n, err := strconv.Atoi(args[i]); err == nil && n > 0 {
+				return n
+			}
+		}
+		return 1
+	}
+	switch final {
+	case 'A':
+		*y = max(*y-arg(0), 0)
+	case 'B':
+		*y += arg(0)
+	case 'C':
+		*x += arg(0)
+	case 'D':
+		*x = max(*x-arg(0), 0)
+	case 'G':
+		*x = arg(0) - 1
+	case 'H', 'f':
+		*y, *x = arg(0)-1, arg(1)-1
+	}
+}
+
+func applySGR(params string, style *cellStyle, base cellStyle) {
+	if params == "" {
+		*style = base
+		return
+	}
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, _ := strconv.Atoi(codes[i])
+		switch {
+		case code == 0:
+			*style = base
+		case code == 1:
+			style.bold = true
+		case code == 3:
+			style.italic = true
+		case code == 4:
+			style.underline = true
+		case code == 7:
+			style.fg, style.bg = style.bg, style.fg
+		case code == 22:
+			style.bold = false
+		case code == 23:
+			style.italic = false
+		case code == 24:
+			style.underline = false
+		case code >= 30 && code <= 37:
+			style.fg = ansiPalette[code-30]
+		case code >= 90 && code <= 97:
+			style.fg = ansiPalette[code-90+8]
+		case code >= 40 && code <= 47:
+			style.bg = ansiPalette[code-40]
+		case code >= 100 && code <= 107:
+			style.bg = ansiPalette[code-100+8]
+		case code == 39:
+			style.fg = base.fg
+		case code == 49:
+			style.bg = base.bg
+		case code == 38 || code == 48:
+			c, consumed := parseExtendedColor(codes[i+1:])
+			i += consumed
+			if consumed == 0 {
+				continue
+			}
+			if code == 38 {
+				style.fg = c
+			} else {
+				style.bg = c
+			}
+		}
+	}
+}
+
+// parseExtendedColor parses the arguments of a 38/48 SGR code: "5;n" or
+// "2;r;g;b". It returns the number of parameters consumed.
+func parseExtendedColor(args []string) (color.RGBA, int) {
+	if len(args) == 0 {
+		return color.RGBA{}, 0
+	}
+	switch args[0] {
+	case "5":
+		if len(args) < 2 {
+			return color.RGBA{}, len(args)
+		}
+		n, _ := strconv.Atoi(args[1])
+		return xterm256(clampInt(n, 0, 255)), 2
+	case "2":
+		if len(args) < 4 {
+			return color.RGBA{}, len(args)
+		}
+		r, _ := strconv.Atoi(args[1])
+		g, _ := strconv.Atoi(args[2])
+		b, _ := strconv.Atoi(args[3])
+		return color.RGBA{uint8(r), uint8(g), uint8(b), 0xFF}, 4
+	}
+	return color.RGBA{}, 1
+}
+
+// runs splits row y into maximal spans of cells sharing a style, calling fn
+// with the starting column, the number of cells and the span's runes, each
+// paired with the column offset it starts at.
+func (s *screen) runs(y int, fn func(x, n int, text []rune, offsets []int, style cellStyle)) {
+	row := s.cells[y]
+	start := 0
+	for x := 1; x <= len(row); x++ {
+		if x < len(row) && row[x].style == row[start].style {
+			continue
+		}
+		var text []rune
+		var offsets []int
+		for i, c := range row[start:x] {
+			if c.r != 0 {
+				text = append(text, c.r)
+				offsets = append(offsets, i)
+			}
+		}
+		fn(start, x-start, text, offsets, row[start].style)
+		start = x
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+)
+
+// rowText returns row y of s as text, wide runes once.
+func rowText(s *screen, y int) string {
+	var text []rune
+	for _, c := range s.cells[y] {
+		if c.r != 0 {
+			text = append(text, c.r)
+		}
+	}
+	return string(text)
+}
+
+func TestParseScreenColors(t *testing.T) {
+	s := parseScreen("\x1b[1;31ma\x1b[0mb\x1b[38;5;21;48;2;1;2;3mc\x1b[39;49;4md\x1b[7me", 5, 1, darkPage)
+	row := s.cells[0]
+	tests := []struct {
+		x    int
+		want cellStyle
+	}{
+		{0, cellStyle{fg: ansiPalette[1], bg: darkPage.bg, bold: true}},
+		{1, cellStyle{fg: darkPage.fg, bg: darkPage.bg}},
+		{2, cellStyle{fg: color.RGBA{0x00, 0x00, 0xFF, 0xFF}, bg: color.RGBA{1, 2, 3, 0xFF}}},
+		{3, cellStyle{fg: darkPage.fg, bg: darkPage.bg, underline: true}},
+		{4, cellStyle{fg: darkPage.bg, bg: darkPage.fg, underline: true}},
+	}
+	for _, tt := range tests {
+		if got := row[tt.x].style; got != tt.want {
+			t.Errorf("cell %d (%c) style = %+v, want %+v", tt.x, row[tt.x].r, got, tt.want)
+		}
+	}
+	if s.page != darkPage.bg {
+		t.Errorf("page = %v", s.page)
+	}
+}
+
+func TestParseScreenWideRunes(t *testing.T) {
+	s := parseScreen("日本x\n界界界", 5, 2, lightPage)
+	if got := rowText(s, 0); got != "日本x" {
+		t.Errorf("row 0 = %q", got)
+	}
+	if s.cells[0][1].r != 0 || s.cells[0][4].r != 'x' {
+		t.Errorf("wide runes don't take two cells: %+v", s.cells[0])
+	}
+	// A wide rune that doesn't fit in the last column is dropped
+	if got := rowText(s, 1); got != "界界 " {
+		t.Errorf("row 1 = %q", got)
+	}
+}
+
+func TestParseScreenCursorMoves(t *testing.T) {
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{"abc\rX\nde", []string{"Xbc  ", "de   "}},
+		{"a\x1b[2Cb\x1b[3Dc", []string{"ac b "}},
+		{"\x1b[2;4Hx\x1b[Hy\x1b[1By\x1b[Az", []string{"y z  ", " y x "}},
+		{"ab\x1b[5Gc\x1b]8;;http://x\x07d\x1b[K", []string{"ab  c", "     "}},
+		{"\x1b[3Bfar below", []string{"     ", "     "}},
+	}
+	for _, tt := range tests {
+		s := parseScreen(tt.output, 5, 2, darkPage)
+		for y, want := range tt.want {
+			if got := rowText(s, y); got != want {
+				t.Errorf("parseScreen(%q) row %d = %q, want %q", tt.output, y, got, want)
+			}
+		}
+	}
+}
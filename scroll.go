@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// wheelLines is how far one mouse wheel notch scrolls a slide.
+const wheelLines = 3
+
+// slideLines renders slide index at its current reveal step, search matches
+// highlighted, as the lines View lays out.
+func (m model) slideLines(index int) []string {
+	lines, _ := m.renderSlide(index)
+	return lines
+}
+
+// renderSlide is slideLines, also returning what must be written ahead of
+// the lines for their images to show.
+func (m model) renderSlide(index int) ([]string, string) {
+	s := m.slides[index]
+	markdown := slideMarkdownAt(s, m.revealProgress[index], m.morphProgress(index))
+	width := m.wrapWidth()
+	if m.config.fits() && !hasColumns(markdown, s.meta.Layout) {
+		width = m.fitWidth(index)
+	}
+
+	// Slides with columns render each at its own width
+	var prelude string
+	render := func(markdown string, width int) []string {
+		var images []slideImage
+		if m.images != imagesOff {
+			markdown, images = placeImages(markdown, filepath.Dir(s.path))
+		}
+		r := m.renderer
+		if width != m.wrapWidth() {
+			r = m.fit.renderer(m.config.themeName(), width)
+		}
+		rendered, err := r.Render(markdown)
+		if err != nil {
+			rendered = "Error rendering markdown: " + err.Error()
+		}
+		rendered = dimHidden(rendered)
+		if m.searchQuery != "" {
+			rendered = highlightMatches(rendered, m.searchQuery)
+		}
+		lines := strings.Split(strings.TrimRight(rendered, "\n"), "\n")
+		lines, graphics := expandImages(lines, images, m.images, width, m.contentHeight(index))
+		prelude += graphics
+		return lines
+	}
+	lines := renderLayout(markdown, s.meta.Layout, width, render)
+
+	horizontal, vertical := m.centering(index)
+	if vertical {
+		lines = trimBlankLines(lines)
+	}
+	if horizontal {
+		lines = centerLines(lines, m.width)
+	}
+	return lines, prelude
+}
+
+// centering returns whether slide index is centered across and top to
+// bottom, by the deck's center setting or the slide's layout. Fit mode
+// centers slides both ways, as it wraps them narrower than the screen.
+func (m model) centering(index int) (horizontal, vertical bool) {
+	horizontal, vertical = layoutCentering(m.slides[index].meta.Layout)
+	return horizontal || m.config.centersHorizontally() || m.config.fits(), vertical || m.config.centersVertically() || m.config.fits()
+}
+
+// contentHeight returns how many lines are left for slide index once the
+// bars below it are drawn.
+func (m model) contentHeight(index int) int {
+	height := m.height - 2 // status + progress
+	height -= len(renderCommandHotkeys(m.slides[index].commands, m.width))
+	if m.notification != "" || m.prompting {
+		height-- // additional line for notification or prompt
+	}
+	if m.timerDuration > 0 {
+		height -= 2 // timer display + timer progress bar
+	}
+	return height
+}
+
+// scrollWindow returns the height lines of lines to show, starting offset
+// lines down, and the offset clamped to the content. Content taller than
+// height gives up its last line to an indicator of what is scrolled out of
+// view.
+func scrollWindow(lines []string, height, offset int) ([]string, int) {
+	if len(lines) <= height || height < 2 {
+		return lines[:min(len(lines), max(height, 0))], 0
+	}
+	visible := height - 1
+	offset = clampInt(offset, 0, len(lines)-visible)
+	window := append([]string{}, lines[offset:offset+visible]...)
+
+	var parts []string
+	if offset > 0 {
+		parts = append(parts, fmt.Sprintf("↑ %d above", offset))
+	}
+	if below := len(lines) - offset - visible; below > 0 {
+		parts = append(parts, fmt.Sprintf("↓ %d below", below))
+	}
+	indicator := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#94A3B8")).
+		PaddingLeft(2).
+		Render(strings.Join(parts, " · ") + " · PgUp/PgDn to scroll")
+	return append(window, indicator), offset
+}
+
+// scrollSlide moves the current slide's content by delta lines.
+func (m model) scrollSlide(delta int) (tea.Model, tea.Cmd) {
+	if len(m.slides) == 0 {
+		return m, nil
+	}
+	if m.scrollOffsets == nil {
+		m.scrollOffsets = make(map[int]int)
+	}
+	_, offset := scrollWindow(m.slideLines(m.currentSlide), m.contentHeight(m.currentSlide), m.scrollOffsets[m.currentSlide]+delta)
+	m.scrollOffsets[m.currentSlide] = offset
+	return m, nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchHighlight marks search matches in the rendered slide.
+var searchHighlight = lipgloss.NewStyle().
+	Background(lipgloss.Color("#F59E0B")).
+	Foreground(lipgloss.Color("#000000"))
+
+// newPrompt opens the one-line prompt used by ":" (go to slide) and "/"
+// (search).
+func newPrompt(kind string) textinput.Model {
+	prompt := textinput.New()
+	prompt.Prompt = kind
+	prompt.Focus()
+	return prompt
+}
+
+// searchTerms splits a query into lowercase terms.
+func searchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// splitWords splits text into runs of letters and digits.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// fuzzyMatch reports whether term matches word: term occurs in word, or
+// word starts with term's first letter and contains the rest of it in order,
+// so "brnch" matches "branch". Both must be lowercase.
+func fuzzyMatch(term, word string) bool {
+	if strings.Contains(word, term) {
+		return true
+	}
+	if term == "" || word == "" || term[0] != word[0] {
+		return false
+	}
+	rest := []rune(term)
+	for _, r := range word {
+		if len(rest) > 0 && r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
+
+// matchesTerm reports whether any word of text matches term.
+func matchesTerm(term, text string) bool {
+	for _, word := range splitWords(strings.ToLower(text)) {
+		if fuzzyMatch(term, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchSlides returns the indices of the slides whose title or text,
+// fully revealed, matches every term of query.
+func searchSlides(slides []slide, query string) []int {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	var results []int
+	for i, s := range slides {
+		text := slideTitle(s) + "\n" + slideText(s, s.reveal.totalItems())
+		matched := true
+		for _, term := range terms {
+			if !matchesTerm(term, text) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			results = append(results, i)
+		}
+	}
+	return results
+}
+
+// highlightMatches marks the words of rendered that match any term of query.
+// rendered is glamour output; matches are found in its visible text and
+// restyled in place.
+func highlightMatches(rendered, query string) string {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		plain := []rune(ansi.Strip(line))
+		// Walk the visible text backwards so earlier cell offsets stay valid
+		end := len(plain)
+		for end > 0 {
+			for end > 0 && !isWordRune(plain[end-1]) {
+				end--
+			}
+			start := end
+			for start > 0 && isWordRune(plain[start-1]) {
+				start--
+			}
+			if start == end {
+				break
+			}
+			word := strings.ToLower(string(plain[start:end]))
+			for _, term := range terms {
+				if fuzzyMatch(term, word) {
+					left := ansi.StringWidth(string(plain[:start]))
+					right := left + ansi.StringWidth(string(plain[start:end]))
+					line = ansi.Truncate(line, left, "") + searchHighlight.Render(string(plain[start:end])) + ansi.TruncateLeft(line, right, "")
+					break
+				}
+			}
+			end = start
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// goToSlide shows the slide at index, moving the progress bar as the
+// arrow keys do.
+func (m model) goToSlide(index int) (tea.Model, tea.Cmd) {
+	m.currentSlide = clampInt(index, 0, len(m.slides)-1)
+	percentage := float64(m.currentSlide+1) / float64(len(m.slides))
+	cmd := m.progress.SetPercent(percentage)
+	timerCmd := updateTimerProgress(&m)
+	return m, tea.Batch(cmd, timerCmd)
+}
+
+// nextResult shows the first search result after (delta 1) or before
+// (delta -1) slide from, wrapping around the deck.
+func (m model) nextResult(from, delta int) (tea.Model, tea.Cmd) {
+	if len(m.searchResults) == 0 {
+		if m.searchQuery != "" {
+			m.notification = fmt.Sprintf("No slide matches %q", m.searchQuery)
+			m.notificationTimer = 3
+			return m, doTick()
+		}
+		return m, nil
+	}
+	target := -1
+	for i := range m.searchResults {
+		// Results are in deck order; walk them in the direction of travel
+		j := i
+		if delta < 0 {
+			j = len(m.searchResults) - 1 - i
+		}
+		if (delta > 0 && m.searchResults[j] > from) || (delta < 0 && m.searchResults[j] < from) {
+			target = j
+			break
+		}
+	}
+	if target < 0 {
+		target = 0
+		if delta < 0 {
+			target = len(m.searchResults) - 1
+		}
+	}
+	m.notification = fmt.Sprintf("/%s: match %d of %d", m.searchQuery, target+1, len(m.searchResults))
+	m.notificationTimer = 3
+	next, cmd := m.goToSlide(m.searchResults[target])
+	return next, tea.Batch(cmd, doTick())
+}
+
+// updatePrompt handles keys while the ":" or "/" prompt is open.
+func (m model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.prompting = false
+		return m, nil
+	case tea.KeyEnter:
+		m.prompting = false
+		value := strings.TrimSpace(m.prompt.Value())
+		if m.prompt.Prompt == ":" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > len(m.slides) {
+				m.notification = fmt.Sprintf("No slide %q; the deck has %d slides", value, len(m.slides))
+				m.notificationTimer = 3
+				return m, doTick()
+			}
+			return m.goToSlide(n - 1)
+		}
+		m.searchQuery = value
+		m.searchResults = searchSlides(m.slides, value)
+		// A match on the current slide counts as the first result
+		return m.nextResult(m.currentSlide-1, 1)
+	}
+	var cmd tea.Cmd
+	m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		term, word string
+		want       bool
+	}{
+		{"branch", "branch", true},
+		{"ranc", "branch", true},
+		{"brnch", "branch", true},
+		{"rnch", "branch", false},
+		{"bhcnar", "branch", false},
+		{"branches", "branch", false},
+		{"", "branch", true},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.term, tt.word); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.term, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSearchSlides(t *testing.T) {
+	contents := []string{
+		"# Branches\n\nCreate a virtual branch.\n",
+		"# Commits\n\n:reveal:\n\n- amend a commit\n- squash commits\n",
+		"# Wrap up\n\n```commands\ngit branch -d old\n```\n\nQuestions?\n",
+		"---\ntitle: Undo\n---\nThe oplog restores any snapshot.\n",
+	}
+	slides := make([]slide, len(contents))
+	for i, content := range contents {
+		slides[i], _ = fileSlide("", content)
+	}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"branch", []int{0}},
+		{"BRANCH", []int{0}},
+		{"brnch", []int{0}},
+		// Items a reveal still hides are searched too
+		{"squash", []int{1}},
+		{"commit", []int{1}},
+		{"virtual branch", []int{0}},
+		{"virtual squash", nil},
+		// Command blocks aren't part of the slide text
+		{"old", nil},
+		// Front matter titles are
+		{"undo", []int{3}},
+		{"oplog", []int{3}},
+		{"a", []int{0, 1, 2, 3}},
+	}
+	for _, tt := range tests {
+		if got := searchSlides(slides, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchSlides(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestNextResultWraps(t *testing.T) {
+	m := newStaticModel(".", "", numberedSlides(6), deckConfig{}, 80, 24)
+	m.searchQuery = "slide"
+	m.searchResults = []int{1, 3, 4}
+	tests := []struct {
+		from, delta, want int
+		notification      string
+	}{
+		{0, 1, 1, "/slide: match 1 of 3"},
+		{1, 1, 3, "/slide: match 2 of 3"},
+		{4, 1, 1, "/slide: match 1 of 3"},
+		{5, 1, 1, "/slide: match 1 of 3"},
+		{5, -1, 4, "/slide: match 3 of 3"},
+		{3, -1, 1, "/slide: match 1 of 3"},
+		{1, -1, 4, "/slide: match 3 of 3"},
+		{0, -1, 4, "/slide: match 3 of 3"},
+	}
+	for _, tt := range tests {
+		next, _ := m.nextResult(tt.from, tt.delta)
+		got := next.(model)
+		if got.currentSlide != tt.want || got.notification != tt.notification {
+			t.Errorf("nextResult(%d, %d) went to %d %q, want %d %q", tt.from, tt.delta, got.currentSlide, got.notification, tt.want, tt.notification)
+		}
+	}
+
+	m.searchQuery, m.searchResults = "nothing", nil
+	next, _ := m.nextResult(2, 1)
+	if got := next.(model); got.currentSlide != 0 || !strings.Contains(got.notification, "No slide matches") {
+		t.Errorf("without results: slide %d, notification %q", got.currentSlide, got.notification)
+	}
+}
+
+func TestSearchPromptStartsAtCurrentSlide(t *testing.T) {
+	m := newStaticModel(".", "", numberedSlides(6), deckConfig{}, 80, 24)
+	// A match on the current slide is the first result
+	m = pressKeys(m, "l l / slide enter")
+	if m.currentSlide != 2 || len(m.searchResults) != 6 {
+		t.Errorf("search went to slide %d with %d results", m.currentSlide, len(m.searchResults))
+	}
+	if m = pressKeys(m, "n n n n"); m.currentSlide != 0 {
+		t.Errorf("n didn't wrap to the first slide: at %d", m.currentSlide)
+	}
+	if m = pressKeys(m, "N"); m.currentSlide != 5 {
+		t.Errorf("N didn't wrap to the last slide: at %d", m.currentSlide)
+	}
+	if m = pressKeys(m, "esc"); m.searchQuery != "" || m.searchResults != nil {
+		t.Errorf("esc kept the search %q", m.searchQuery)
+	}
+}
+
+func TestHighlightMatchesStyledLines(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	bold := lipgloss.NewStyle().Bold(true)
+	red := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	tests := []struct {
+		name, line, query string
+		words             []string
+	}{
+		{"plain", "  a virtual branch  ", "branch", []string{"branch"}},
+		{"styled word", "  " + bold.Render("branch") + " list", "branch", []string{"branch"}},
+		{"styled run", red.Render("git branch -d") + " " + bold.Render("old branch"), "brnch", []string{"branch", "branch"}},
+		{"several terms", bold.Render("amend") + " then " + red.Render("squash"), "squash amend", []string{"amend", "squash"}},
+		{"wide runes", "  日本 branch", "branch", []string{"branch"}},
+		{"no match", bold.Render("nothing") + " here", "branch", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := highlightMatches(tt.line, tt.query)
+			if ansi.Strip(got) != ansi.Strip(tt.line) {
+				t.Errorf("text changed from %q to %q", ansi.Strip(tt.line), ansi.Strip(got))
+			}
+			if ansi.StringWidth(got) != ansi.StringWidth(tt.line) {
+				t.Errorf("width changed from %d to %d", ansi.StringWidth(tt.line), ansi.StringWidth(got))
+			}
+			var highlighted []string
+			for _, word := range tt.words {
+				highlighted = append(highlighted, searchHighlight.Render(word))
+			}
+			if len(tt.words) == 0 && got != tt.line {
+				t.Errorf("line without matches changed to %q", got)
+			}
+			rest := got
+			for _, h := range highlighted {
+				i := strings.Index(rest, h)
+				if i < 0 {
+					t.Fatalf("%q is missing highlight %q", got, h)
+				}
+				rest = rest[i+len(h):]
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// newStaticModel builds a model that renders frames of the deck at a fixed
+// size without a terminal, for exports. Colors are always emitted, and the
+// "auto" theme resolves to the dark style since there is no terminal
+// background to detect.
+func newStaticModel(root, deckFile string, slides []slide, cfg deckConfig, width, height int) model {
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	if cfg.themeName() == "auto" {
+		cfg.Theme = "dark"
+	}
+
+	m := initialModel(root, deckFile, nil, nil)
+	m.static = true
+	m.progress = progress.New(progress.WithDefaultGradient(), progress.WithColorProfile(termenv.TrueColor))
+	m.timerProgress = progress.New(progress.WithSolidFill("#FF6B35"), progress.WithColorProfile(termenv.TrueColor))
+
+	next, _ := m.update(tea.WindowSizeMsg{Width: width, Height: height})
+	next, _ = next.(model).update(slidesLoadedMsg{slides: slides, config: cfg})
+	return next.(model)
+}
+
+// revealSteps lists the reveal steps of s, or the single step 0 for slides
+// without reveal directives.
+func revealSteps(s slide) []int {
+	total := s.reveal.totalItems()
+	if total == 0 {
+		return []int{0}
+	}
+	steps := make([]int, total)
+	for i := range steps {
+		steps[i] = i + 1
+	}
+	return steps
+}
+
+// frame renders slide index at reveal step exactly as View shows it.
+func (m model) frame(index, step int) string {
+	m.currentSlide = index
+	shown := make(map[int]int, len(m.revealProgress))
+	for idx, count := range m.revealProgress {
+		shown[idx] = count
+	}
+	shown[index] = clampRevealProgress(step, m.slides[index].reveal.totalItems())
+	m.revealProgress = shown
+	m.progress.SetPercent(float64(index+1) / float64(len(m.slides)))
+	return m.View()
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The audience window and `slidetty presenter` talk over a Unix socket. The
+// audience side listens and broadcasts its state as newline-delimited JSON;
+// the presenter side sends back the keys pressed in its window so either one
+// can drive the talk.
+
+// syncState is the part of the audience model the presenter mirrors.
+type syncState struct {
+	Slide         int           `json:"slide"`
+	Step          int           `json:"step"`
+	TimerDuration time.Duration `json:"timer_duration"`
+	TimerElapsed  time.Duration `json:"timer_elapsed"` // elapsed before TimerStarted
+	TimerStarted  time.Time     `json:"timer_started"`
+	TimerRunning  bool          `json:"timer_running"`
+}
+
+// elapsed returns the total time on the presentation timer.
+func (s syncState) elapsed() time.Duration {
+	if s.TimerRunning {
+		return s.TimerElapsed + time.Since(s.TimerStarted)
+	}
+	return s.TimerElapsed
+}
+
+// syncCommand is sent from the presenter console to the audience window.
+type syncCommand struct {
+	Key string `json:"key"`
+}
+
+// remoteKeys are the keys the presenter console may forward: navigation and
+// timer start/pause.
+var remoteKeys = map[string]bool{
+	"h": true, "j": true, "k": true, "l": true, "w": true,
+}
+
+// remoteKeyMsg is a key forwarded from the presenter console.
+type remoteKeyMsg struct {
+	key string
+}
+
+// syncStateMsg carries a state update from the audience window.
+type syncStateMsg syncState
+
+// syncLostMsg reports that the connection to the audience window closed.
+type syncLostMsg struct{}
+
+// syncSocketPath returns the socket shared by every window presenting the
+// same deck.
+func syncSocketPath(root, deckFile string) string {
+	target := root
+	if deckFile != "" {
+		target = deckFile
+	}
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+	sum := sha1.Sum([]byte(target))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("slidetty-%x.sock", sum[:6]))
+}
+
+// syncServer is the audience side of the presenter connection.
+type syncServer struct {
+	path     string
+	listener net.Listener
+	commands chan string
+	done     chan struct{}
+
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+	last    []byte
+}
+
+// startSyncServer listens on path. A stale socket left by a crashed session
+// is removed; a live one means another audience window owns the deck.
+func startSyncServer(path string) (*syncServer, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another slidetty is already presenting this deck")
+	}
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &syncServer{
+		path:     path,
+		listener: listener,
+		commands: make(chan string, 16),
+		done:     make(chan struct{}),
+		clients:  make(map[net.Conn]struct{}),
+	}
+	go s.accept()
+	return s, nil
+}
+
+func (s *syncServer) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.clients[conn] = struct{}{}
+		if s.last != nil {
+			conn.Write(s.last)
+		}
+		s.mu.Unlock()
+		go s.read(conn)
+	}
+}
+
+func (s *syncServer) read(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var cmd syncCommand
+		if err := json.Unmarshal(scanner.Bytes(), &cmd); err != nil || !remoteKeys[cmd.Key] {
+			continue
+		}
+		select {
+		case s.commands <- cmd.Key:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// publish sends state to every connected presenter console. Unchanged state
+// is not resent.
+func (s *syncServer) publish(state syncState) {
+	if s == nil {
+		return
+	}
+	line, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if string(line) == string(s.last) {
+		return
+	}
+	s.last = line
+	for conn := range s.clients {
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Write(line); err != nil {
+			delete(s.clients, conn)
+			conn.Close()
+		}
+	}
+}
+
+// wait returns a command that blocks until the presenter forwards a key.
+func (s *syncServer) wait() tea.Cmd {
+	if s == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case key := <-s.commands:
+			return remoteKeyMsg{key: key}
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// Close stops listening, disconnects presenters and removes the socket.
+func (s *syncServer) Close() {
+	if s == nil {
+		return
+	}
+	close(s.done)
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.clients {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	os.Remove(s.path)
+}
+
+// syncClient is the presenter side of the connection.
+type syncClient struct {
+	conn   net.Conn
+	states chan syncState
+}
+
+// dialSync connects to the audience window presenting the deck at path.
+func dialSync(path string) (*syncClient, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	c := &syncClient{conn: conn, states: make(chan syncState, 16)}
+	go c.read()
+	return c, nil
+}
+
+func (c *syncClient) read() {
+	defer close(c.states)
+	scanner := bufio.NewScanner(c.conn)
+	for scanner.Scan() {
+		var state syncState
+		if err := json.Unmarshal(scanner.Bytes(), &state); err == nil {
+			c.states <- state
+		}
+	}
+}
+
+// wait returns a command that blocks until the next state update.
+func (c *syncClient) wait() tea.Cmd {
+	return func() tea.Msg {
+		state, ok := <-c.states
+		if !ok {
+			return syncLostMsg{}
+		}
+		return syncStateMsg(state)
+	}
+}
+
+// send forwards a key pressed in the presenter console.
+func (c *syncClient) send(key string) error {
+	line, err := json.Marshal(syncCommand{Key: key})
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.Write(append(line, '\n'))
+	return err
+}
+
+// Close disconnects from the audience window.
+func (c *syncClient) Close() {
+	c.conn.Close()
+}
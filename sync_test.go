@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// within runs cmd, failing the test if it takes longer than a second.
+func within(t *testing.T, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	msgs := make(chan tea.Msg, 1)
+	go func() { msgs <- cmd() }()
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message within a second")
+		return nil
+	}
+}
+
+func TestSyncProtocol(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync.sock")
+	server, err := startSyncServer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := startSyncServer(path); err == nil {
+		t.Error("a second audience window took over a socket in use")
+	}
+
+	// A presenter connecting late gets the last state straight away
+	server.publish(syncState{Slide: 2, Step: 1})
+	client, err := dialSync(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if msg := within(t, client.wait()); msg != syncStateMsg(syncState{Slide: 2, Step: 1}) {
+		t.Errorf("first state = %+v", msg)
+	}
+	// Unchanged state isn't sent again
+	server.publish(syncState{Slide: 2, Step: 1})
+	server.publish(syncState{Slide: 3})
+	if msg := within(t, client.wait()); msg != syncStateMsg(syncState{Slide: 3}) {
+		t.Errorf("next state = %+v", msg)
+	}
+
+	// Only navigation and timer keys are forwarded
+	for _, key := range []string{"q", "j", "E", "w"} {
+		if err := client.send(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range []string{"j", "w"} {
+		if msg := within(t, server.wait()); msg != (remoteKeyMsg{key: want}) {
+			t.Errorf("forwarded %+v, want key %q", msg, want)
+		}
+	}
+
+	server.Close()
+	if msg := within(t, client.wait()); msg != (syncLostMsg{}) {
+		t.Errorf("after the audience window closed: %+v", msg)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket left behind: %v", err)
+	}
+}
+
+func TestRemoteKeysDriveTheModel(t *testing.T) {
+	slides := []slide{
+		newSlide("# One\n", "# One\n", slideMeta{}, "01.md", -1),
+		newSlide("# Two\n", "# Two\n", slideMeta{}, "02.md", -1),
+	}
+	m := newStaticModel(".", "", slides, deckConfig{}, 80, 24)
+	next, _ := m.Update(remoteKeyMsg{key: "l"})
+	if got := next.(model).syncState().Slide; got != 1 {
+		t.Errorf("slide after a forwarded l = %d, want 1", got)
+	}
+}
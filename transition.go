@@ -0,0 +1,191 @@
+package main
+
+import (
+	"hash/fnv"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Transitions between slides, set with the transition key.
+const (
+	transitionNone       = "none"
+	transitionSlide      = "slide"       // slides move left going forward, right going back
+	transitionSlideLeft  = "slide-left"  // the new slide comes in from the right
+	transitionSlideRight = "slide-right" // the new slide comes in from the left
+	transitionWipe       = "wipe"        // the new slide is uncovered across the screen
+	transitionFade       = "fade"        // the old slide fades out, then the new one in
+	transitionDissolve   = "dissolve"    // the new slide appears a cell at a time
+)
+
+// transitionDuration is how long a transition between slides takes.
+const transitionDuration = 350 * time.Millisecond
+
+// frameInterval is the time between frames of an animation. A frame
+// arriving far later than that means the terminal can't keep up, and
+// animations stop for the rest of the presentation.
+const (
+	frameInterval = time.Second / 30
+	frameLate     = 200 * time.Millisecond
+)
+
+// slideTransition is a transition playing out from the slide shown before.
+type slideTransition struct {
+	id       int      // tells its ticks from those of transitions it replaced
+	from     []string // the previous slide as it was on screen
+	slide    int      // the slide transitioned to
+	style    string
+	forward  bool
+	dark     bool // the terminal background is dark, for fades
+	start    time.Time
+	lastTick time.Time
+}
+
+// transitionTickMsg moves transition id on a frame. Ticks of a transition
+// that another has replaced are dropped, so each plays at one frame rate.
+type transitionTickMsg struct{ id int }
+
+func doTransitionTick(id int) tea.Cmd {
+	return tea.Tick(frameInterval, func(t time.Time) tea.Msg {
+		return transitionTickMsg{id: id}
+	})
+}
+
+// motionAllowed reports whether animations may play: not when NO_MOTION is
+// set. Links too slow for them, such as some SSH sessions, are found by how
+// late frames arrive.
+func motionAllowed() bool {
+	return os.Getenv("NO_MOTION") == ""
+}
+
+// transitionStyle returns the transition into slide index: the slide's own,
+// else the deck's.
+func (m model) transitionStyle(index int) string {
+	if style := m.slides[index].meta.Transition; style != "" {
+		return style
+	}
+	return m.config.Transition
+}
+
+// startTransition starts a transition on m when it shows a different slide
+// than prev did.
+func startTransition(m *model, prev model) tea.Cmd {
+	if m.static || m.slowLink || !motionAllowed() || len(prev.slides) != len(m.slides) || prev.currentSlide == m.currentSlide ||
+		m.currentSlide >= len(m.slides) || m.showEditor || m.showOverview || prev.showEditor || prev.showOverview ||
+		m.images == imagesITerm || m.images == imagesSixel {
+		return nil
+	}
+	style := m.transitionStyle(m.currentSlide)
+	if style == "" || style == transitionNone {
+		return nil
+	}
+	from, _ := prev.slideArea(prev.currentSlide, m.contentHeight(m.currentSlide))
+	now := time.Now()
+	m.transitions++
+	m.transition = &slideTransition{
+		id:       m.transitions,
+		from:     from,
+		slide:    m.currentSlide,
+		style:    style,
+		forward:  m.currentSlide > prev.currentSlide,
+		dark:     lipgloss.HasDarkBackground(),
+		start:    now,
+		lastTick: now,
+	}
+	return doTransitionTick(m.transitions)
+}
+
+// progress returns how far the transition has got, from 0 to 1.
+func (t *slideTransition) progress() float64 {
+	return min(float64(time.Since(t.start))/float64(transitionDuration), 1)
+}
+
+// frame blends the previous slide's lines with to, the lines of the slide
+// transitioned to, as they appear progress of the way through.
+func (t *slideTransition) frame(to []string, width int, progress float64) []string {
+	frame := make([]string, len(to))
+	offset := int(progress * float64(width))
+	for y := range to {
+		var from string
+		if y < len(t.from) {
+			from = t.from[y]
+		}
+		from, next := padLine(from, width), padLine(to[y], width)
+
+		switch t.style {
+		case transitionSlide, transitionSlideLeft, transitionSlideRight:
+			if t.style == transitionSlideLeft || t.style == transitionSlide && t.forward {
+				frame[y] = ansi.Cut(from, offset, width) + ansi.ResetStyle + ansi.Cut(next, 0, offset)
+			} else {
+				frame[y] = ansi.Cut(next, width-offset, width) + ansi.ResetStyle + ansi.Cut(from, 0, width-offset)
+			}
+		case transitionWipe:
+			if t.forward {
+				frame[y] = ansi.Cut(next, 0, offset) + ansi.ResetStyle + ansi.Cut(from, offset, width)
+			} else {
+				frame[y] = ansi.Cut(from, 0, width-offset) + ansi.ResetStyle + ansi.Cut(next, width-offset, width)
+			}
+		case transitionFade:
+			frame[y] = t.fadeLine(from, next, progress)
+		case transitionDissolve:
+			frame[y] = dissolveLine(from, next, y, width, progress)
+		default:
+			frame[y] = to[y]
+		}
+	}
+	return frame
+}
+
+// fadeLine fades from out towards the background during the first half of
+// the transition, and next in from it during the second. Lines lose their
+// own colors while they fade.
+func (t *slideTransition) fadeLine(from, next string, progress float64) string {
+	foreground, background := colorful.Color{R: 0.9, G: 0.9, B: 0.92}, colorful.Color{}
+	if !t.dark {
+		foreground, background = colorful.Color{R: 0.12, G: 0.16, B: 0.22}, colorful.Color{R: 1, G: 1, B: 1}
+	}
+	line, color := from, foreground.BlendLab(background, progress*2)
+	if progress >= 0.5 {
+		line, color = next, background.BlendLab(foreground, progress*2-1)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color.Clamped().Hex())).Render(ansi.Strip(line))
+}
+
+// dissolveLine shows each cell of row y from next once progress passes a
+// threshold scattered across the screen, and from from until then.
+func dissolveLine(from, next string, y, width int, progress float64) string {
+	var b strings.Builder
+	runStart, runNext := 0, false
+	for x := 0; x <= width; x++ {
+		isNext := false
+		if x < width {
+			h := fnv.New32a()
+			h.Write([]byte{byte(x), byte(x >> 8), byte(y), byte(y >> 8)})
+			isNext = float64(h.Sum32()%1000)/1000 < progress
+		}
+		if x > 0 && (x == width || isNext != runNext) {
+			// Each run of cells is cut whole from one slide or the other
+			source := from
+			if runNext {
+				source = next
+			}
+			b.WriteString(ansi.Cut(source, runStart, x) + ansi.ResetStyle)
+			runStart = x
+		}
+		runNext = isNext
+	}
+	return b.String()
+}
+
+// padLine pads line with spaces to width columns.
+func padLine(line string, width int) string {
+	if w := ansi.StringWidth(line); w < width {
+		return line + strings.Repeat(" ", width-w)
+	}
+	return line
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaleTransitionTicksAreDropped(t *testing.T) {
+	slides := []slide{newSlide("# One\n", "# One\n", slideMeta{}, "01.md", -1)}
+	m := newStaticModel(".", "", slides, deckConfig{}, 80, 24)
+	now := time.Now()
+	m.transition = &slideTransition{id: 2, slide: 0, style: transitionWipe, start: now, lastTick: now}
+
+	next, cmd := m.update(transitionTickMsg{id: 1})
+	if cmd != nil {
+		t.Error("a tick of a replaced transition scheduled another tick")
+	}
+	if next.(model).transition == nil {
+		t.Fatal("a tick of a replaced transition ended the current one")
+	}
+	if _, cmd := next.(model).update(transitionTickMsg{id: 2}); cmd == nil {
+		t.Error("the current transition's tick didn't schedule the next frame")
+	}
+}
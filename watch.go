@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// watchDebounce coalesces the burst of events editors produce when
+	// saving (write to a temp file, rename over, chmod...).
+	watchDebounce = 150 * time.Millisecond
+	// watchPollInterval is how often the polling fallback rescans the deck.
+	watchPollInterval = time.Second
+)
+
+// deckChangedMsg is sent whenever a file of the deck changes on disk.
+type deckChangedMsg struct{}
+
+// deckWatcher notifies the presenter about changes in the deck directory.
+// It uses inotify (via fsnotify) where available and falls back to polling
+// file modification times otherwise.
+type deckWatcher struct {
+	root    string
+	changes chan struct{}
+	done    chan struct{}
+	fs      *fsnotify.Watcher
+	watched map[string]bool // directories added to fs
+}
+
+// newDeckWatcher starts watching root, and the directories of the decks it
+// includes. It never fails: if the platform watcher cannot be set up the
+// deck is polled instead.
+func newDeckWatcher(root string) *deckWatcher {
+	w := &deckWatcher{
+		root:    root,
+		changes: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		watched: make(map[string]bool),
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = fw.Add(root); err == nil {
+			w.fs = fw
+			w.watchDeckDirs()
+			go w.watchEvents()
+			return w
+		}
+		fw.Close()
+	}
+	go w.poll()
+	return w
+}
+
+// dirs returns the directories holding the deck's files.
+func (w *deckWatcher) dirs() []string {
+	return append([]string{w.root}, deckDirs(w.root)...)
+}
+
+// watchDeckDirs adds the directories the deck reads files from to the
+// watcher, as they change with the manifests.
+func (w *deckWatcher) watchDeckDirs() {
+	for _, dir := range deckDirs(w.root) {
+		if !w.watched[dir] && w.fs.Add(dir) == nil {
+			w.watched[dir] = true
+		}
+	}
+}
+
+// isDeckFile reports whether a change to name can affect the deck: slides,
+// deck.yaml and the underscore metadata files. Editor swap and backup files
+// are ignored.
+func isDeckFile(name string) bool {
+	base := filepath.Base(name)
+	if strings.HasPrefix(base, ".") || strings.HasSuffix(base, "~") {
+		return false
+	}
+	return filepath.Ext(base) == ".md" || base == deckConfigFile || strings.HasPrefix(base, "_")
+}
+
+// notify queues a change without blocking; one pending change is enough to
+// trigger a full reload.
+func (w *deckWatcher) notify() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (w *deckWatcher) watchEvents() {
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if isDeckFile(event.Name) {
+				debounce = time.After(watchDebounce)
+			}
+		case _, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+		case <-debounce:
+			debounce = nil
+			// A manifest may have changed what the deck includes
+			w.watchDeckDirs()
+			w.notify()
+		}
+	}
+}
+
+// snapshot records the size and modification time of every deck file.
+func (w *deckWatcher) snapshot() map[string]string {
+	snap := make(map[string]string)
+	for _, dir := range w.dirs() {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() || !isDeckFile(file.Name()) {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			snap[filepath.Join(dir, file.Name())] = fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return snap
+}
+
+func (w *deckWatcher) poll() {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	last := w.snapshot()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			next := w.snapshot()
+			if !sameSnapshot(last, next) {
+				w.notify()
+			}
+			last = next
+		}
+	}
+}
+
+func sameSnapshot(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, stamp := range a {
+		if b[name] != stamp {
+			return false
+		}
+	}
+	return true
+}
+
+// wait returns a command that blocks until the next change. It is re-issued
+// after every deckChangedMsg to keep listening.
+func (w *deckWatcher) wait() tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case <-w.changes:
+			return deckChangedMsg{}
+		case <-w.done:
+			return nil
+		}
+	}
+}
+
+// Close stops watching.
+func (w *deckWatcher) Close() {
+	if w == nil {
+		return
+	}
+	close(w.done)
+	if w.fs != nil {
+		w.fs.Close()
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// includingDeck lays out a deck in main that includes the deck in shared
+// and a slide in extra, and returns main and the two directories.
+func includingDeck(t *testing.T) (root, shared, extra string) {
+	dir := t.TempDir()
+	root, shared, extra = filepath.Join(dir, "main"), filepath.Join(dir, "shared"), filepath.Join(dir, "main", "extra")
+	for _, d := range []string{root, shared, extra} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(root, "_order"):  "01.md\nextra/02.md\ninclude ../shared\n",
+		filepath.Join(root, "01.md"):   "# One\n",
+		filepath.Join(extra, "02.md"):  "# Two\n",
+		filepath.Join(shared, "01.md"): "# Shared\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, shared, extra
+}
+
+func TestDeckDirs(t *testing.T) {
+	root, shared, extra := includingDeck(t)
+	if got, want := deckDirs(root), []string{extra, shared}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deckDirs = %q, want %q", got, want)
+	}
+}
+
+func TestWatcherSeesIncludedDecks(t *testing.T) {
+	root, shared, _ := includingDeck(t)
+	w := newDeckWatcher(root)
+	defer w.Close()
+	if w.fs == nil {
+		t.Skip("no file system notifications here")
+	}
+
+	if err := os.WriteFile(filepath.Join(shared, "01.md"), []byte("# Shared, edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported for a slide of an included deck")
+	}
+}
+
+func TestReloadClearsLoadError(t *testing.T) {
+	slides := []slide{newSlide("# One\n", "# One\n", slideMeta{}, "01.md", -1)}
+	m := newStaticModel(".", "", slides, deckConfig{}, 80, 24)
+
+	next, _ := m.update(errMsg(errors.New("deck.yaml: yaml: line 2: mapping values are not allowed here")))
+	if next.(model).err == nil {
+		t.Fatal("a failed reload didn't report its error")
+	}
+	next, _ = next.(model).update(slidesLoadedMsg{slides: slides})
+	if err := next.(model).err; err != nil {
+		t.Errorf("the error stayed after the deck reloaded: %v", err)
+	}
+	if view := next.(model).View(); strings.Contains(view, "Error:") {
+		t.Errorf("the view still shows the error:\n%s", view)
+	}
+}